@@ -23,7 +23,7 @@ func New() *App {
 	pkglog.InitLogging()
 	app.initConfig()
 	app.initHTTPServer()
-	app.initModules()
 	app.initClosers()
+	app.initModules()
 	return app
 }
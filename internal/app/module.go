@@ -9,12 +9,15 @@ import (
 
 func (a *App) initModules() {
 	if a.config.GetBool("modules.book-cabin.enabled") {
-		if err := bc.New(bc.Dependency{
+		module, err := bc.New(bc.Dependency{
 			Config: a.config,
 			Router: a.router,
-		}); err != nil {
+			UUID:   a.uuid,
+		})
+		if err != nil {
 			slog.Error("failed to init module book-cabin", "error", err)
 			os.Exit(1)
 		}
+		a.closerFn["BookCabin"] = module.Close
 	}
 }
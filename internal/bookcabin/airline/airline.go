@@ -0,0 +1,41 @@
+package airline
+
+import "errors"
+
+const (
+	TypeLCC         = "lcc"
+	TypeFullService = "full_service"
+
+	AllianceSkyTeam      = "skyteam"
+	AllianceStarAlliance = "star_alliance"
+	AllianceOneworld     = "oneworld"
+)
+
+var ErrInvalidDataset = errors.New("invalid airline dataset")
+
+type Airline struct {
+	Code     string
+	ICAO     string
+	Name     string
+	Aliases  []string
+	Type     string
+	Alliance string
+}
+
+type Directory interface {
+	// Airline looks an airline up by its IATA or ICAO code, canonical name, or
+	// one of its aliases, ignoring case.
+	Airline(key string) (Airline, bool)
+	// Airlines lists every known airline ordered by IATA code.
+	Airlines() []Airline
+}
+
+// IsType reports whether value is one of the supported airline types.
+func IsType(value string) bool {
+	return value == TypeLCC || value == TypeFullService
+}
+
+// IsAlliance reports whether value is one of the supported alliances.
+func IsAlliance(value string) bool {
+	return value == AllianceSkyTeam || value == AllianceStarAlliance || value == AllianceOneworld
+}
@@ -0,0 +1,119 @@
+package airline
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed airlines.json
+var embeddedDataset []byte
+
+// Registry is the airline reference data: codes, canonical names, aliases,
+// carrier type and alliance.
+type Registry struct {
+	airlines []Airline
+	keys     map[string]int
+}
+
+// NewRegistry loads the dataset embedded in the binary.
+func NewRegistry() (*Registry, error) {
+	return parseRegistry(embeddedDataset)
+}
+
+// NewRegistryFromFile loads a dataset with the same layout as the embedded
+// one, replacing it entirely.
+func NewRegistryFromFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("airlines read file: %w", err)
+	}
+	return parseRegistry(data)
+}
+
+func parseRegistry(data []byte) (*Registry, error) {
+	var payload struct {
+		Airlines []struct {
+			Code     string   `json:"code"`
+			ICAO     string   `json:"icao"`
+			Name     string   `json:"name"`
+			Aliases  []string `json:"aliases"`
+			Type     string   `json:"type"`
+			Alliance string   `json:"alliance"`
+		} `json:"airlines"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("airlines decode: %w", err)
+	}
+
+	reg := &Registry{
+		airlines: make([]Airline, 0, len(payload.Airlines)),
+		keys:     make(map[string]int),
+	}
+	for _, a := range payload.Airlines {
+		code := strings.ToUpper(strings.TrimSpace(a.Code))
+		if code == "" || strings.TrimSpace(a.Name) == "" {
+			return nil, fmt.Errorf("%w: airline %q needs a code and a name", ErrInvalidDataset, a.Name)
+		}
+		airlineType := strings.ToLower(a.Type)
+		if !IsType(airlineType) {
+			return nil, fmt.Errorf("%w: airline %s has unknown type %q", ErrInvalidDataset, code, a.Type)
+		}
+		alliance := strings.ToLower(a.Alliance)
+		if alliance != "" && !IsAlliance(alliance) {
+			return nil, fmt.Errorf("%w: airline %s has unknown alliance %q", ErrInvalidDataset, code, a.Alliance)
+		}
+
+		reg.airlines = append(reg.airlines, Airline{
+			Code:     code,
+			ICAO:     strings.ToUpper(strings.TrimSpace(a.ICAO)),
+			Name:     strings.TrimSpace(a.Name),
+			Aliases:  append([]string(nil), a.Aliases...),
+			Type:     airlineType,
+			Alliance: alliance,
+		})
+	}
+	sort.Slice(reg.airlines, func(i, j int) bool { return reg.airlines[i].Code < reg.airlines[j].Code })
+
+	// Codes are indexed first so a name or alias can never shadow them.
+	for i, a := range reg.airlines {
+		if _, ok := reg.keys[lookupKey(a.Code)]; ok {
+			return nil, fmt.Errorf("%w: duplicate airline code %s", ErrInvalidDataset, a.Code)
+		}
+		reg.keys[lookupKey(a.Code)] = i
+	}
+	for i, a := range reg.airlines {
+		for _, key := range append([]string{a.ICAO, a.Name}, a.Aliases...) {
+			key = lookupKey(key)
+			if key == "" {
+				continue
+			}
+			if existing, ok := reg.keys[key]; ok && existing != i {
+				return nil, fmt.Errorf("%w: %q is used by both %s and %s", ErrInvalidDataset, key, reg.airlines[existing].Code, a.Code)
+			}
+			reg.keys[key] = i
+		}
+	}
+
+	return reg, nil
+}
+
+func lookupKey(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func (r *Registry) Airline(key string) (Airline, bool) {
+	i, ok := r.keys[lookupKey(key)]
+	if !ok {
+		return Airline{}, false
+	}
+	return r.airlines[i], true
+}
+
+func (r *Registry) Airlines() []Airline {
+	return append([]Airline(nil), r.airlines...)
+}
@@ -0,0 +1,76 @@
+package airline
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	reg, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	tests := []struct {
+		key      string
+		wantCode string
+	}{
+		{key: "GA", wantCode: "GA"},
+		{key: "ga", wantCode: "GA"},
+		{key: "GIA", wantCode: "GA"},
+		{key: "Garuda Indonesia", wantCode: "GA"},
+		{key: "  garuda  ", wantCode: "GA"},
+		{key: "Air  Asia", wantCode: "QZ"},
+		{key: "8B", wantCode: "8B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			a, ok := reg.Airline(tt.key)
+			if !ok || a.Code != tt.wantCode {
+				t.Fatalf("expected %s, got %+v (found %v)", tt.wantCode, a, ok)
+			}
+		})
+	}
+	if _, ok := reg.Airline("Unknown Air"); ok {
+		t.Fatalf("expected an unknown airline to be missing")
+	}
+
+	ga, _ := reg.Airline("GA")
+	if ga.Type != TypeFullService || ga.Alliance != AllianceSkyTeam {
+		t.Fatalf("expected Garuda to be a full service SkyTeam carrier, got %+v", ga)
+	}
+}
+
+func TestRegistryAirlinesOrderedByCode(t *testing.T) {
+	reg, err := NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	airlines := reg.Airlines()
+	for i := 1; i < len(airlines); i++ {
+		if airlines[i-1].Code >= airlines[i].Code {
+			t.Fatalf("expected airlines ordered by code, got %s before %s", airlines[i-1].Code, airlines[i].Code)
+		}
+	}
+	airlines[0].Code = "XX"
+	if reg.Airlines()[0].Code == "XX" {
+		t.Fatalf("expected Airlines to return a copy")
+	}
+}
+
+func TestParseRegistryErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing name":           `{"airlines":[{"code":"GA","type":"lcc"}]}`,
+		"unknown type":           `{"airlines":[{"code":"GA","name":"Garuda","type":"charter"}]}`,
+		"unknown alliance":       `{"airlines":[{"code":"GA","name":"Garuda","type":"lcc","alliance":"valuealliance"}]}`,
+		"duplicate code":         `{"airlines":[{"code":"GA","name":"Garuda","type":"lcc"},{"code":"ga","name":"Other","type":"lcc"}]}`,
+		"shared alias":           `{"airlines":[{"code":"GA","name":"Garuda","type":"lcc","aliases":["Air"]},{"code":"JT","name":"Lion","type":"lcc","aliases":["air"]}]}`,
+		"alias shadowing a code": `{"airlines":[{"code":"GA","name":"Garuda","type":"lcc","aliases":["JT"]},{"code":"JT","name":"Lion","type":"lcc"}]}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseRegistry([]byte(data)); !errors.Is(err, ErrInvalidDataset) {
+				t.Fatalf("expected ErrInvalidDataset, got %v", err)
+			}
+		})
+	}
+}
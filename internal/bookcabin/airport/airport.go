@@ -0,0 +1,92 @@
+package airport
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	// Zone data ships with the binary so the registry does not depend on the
+	// host's zoneinfo files.
+	_ "time/tzdata"
+)
+
+var (
+	ErrInvalidDataset = errors.New("invalid airport dataset")
+	ErrUnknownZone    = errors.New("unknown time zone")
+)
+
+type Airport struct {
+	Code      string
+	Name      string
+	City      string
+	Country   string
+	TimeZone  string
+	Location  *time.Location
+	Latitude  float64
+	Longitude float64
+}
+
+type Directory interface {
+	// Airport looks up an airport by its IATA code.
+	Airport(code string) (Airport, bool)
+	// Resolve expands a metropolitan (city) code such as JKT into its
+	// airports. An airport code resolves to itself; unknown codes resolve to
+	// nothing.
+	Resolve(code string) []string
+	// Nearby returns the airports within radiusKm of code, closest first,
+	// not including code itself.
+	Nearby(code string, radiusKm float64) []string
+}
+
+// zoneForLabel maps the Indonesian time zone abbreviations to their IANA
+// zones.
+func zoneForLabel(label string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(label)) {
+	case "WIB":
+		return "Asia/Jakarta", true
+	case "WITA":
+		return "Asia/Makassar", true
+	case "WIT":
+		return "Asia/Jayapura", true
+	default:
+		return "", false
+	}
+}
+
+// LoadLocation resolves an IANA zone name (Asia/Jakarta) or an Indonesian zone
+// label (WIB, WITA, WIT).
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if zone, ok := zoneForLabel(name); ok {
+		name = zone
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrUnknownZone)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownZone, name)
+	}
+	return loc, nil
+}
+
+// IsZoneLabel reports whether value is one of the WIB/WITA/WIT labels.
+func IsZoneLabel(value string) bool {
+	_, ok := zoneForLabel(value)
+	return ok
+}
+
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two airports.
+func DistanceKm(a, b Airport) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -0,0 +1,145 @@
+package airport
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed airports.json
+var embeddedDataset []byte
+
+// Registry is the airport reference data: airports with their city, time
+// zone and coordinates, plus metropolitan codes grouping several airports.
+type Registry struct {
+	airports map[string]Airport
+	metros   map[string][]string
+}
+
+// NewRegistry loads the dataset embedded in the binary.
+func NewRegistry() (*Registry, error) {
+	return parseRegistry(embeddedDataset)
+}
+
+// NewRegistryFromFile loads a dataset with the same layout as the embedded
+// one, replacing it entirely.
+func NewRegistryFromFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("airports read file: %w", err)
+	}
+	return parseRegistry(data)
+}
+
+func parseRegistry(data []byte) (*Registry, error) {
+	var payload struct {
+		Metros []struct {
+			Code     string   `json:"code"`
+			Airports []string `json:"airports"`
+		} `json:"metros"`
+		Airports []struct {
+			Code      string  `json:"code"`
+			Name      string  `json:"name"`
+			City      string  `json:"city"`
+			Country   string  `json:"country"`
+			TimeZone  string  `json:"timezone"`
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"airports"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("airports decode: %w", err)
+	}
+
+	reg := &Registry{
+		airports: make(map[string]Airport, len(payload.Airports)),
+		metros:   make(map[string][]string, len(payload.Metros)),
+	}
+	for _, a := range payload.Airports {
+		code := strings.ToUpper(a.Code)
+		loc, err := LoadLocation(a.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: airport %s: %w", ErrInvalidDataset, code, err)
+		}
+		reg.airports[code] = Airport{
+			Code:      code,
+			Name:      a.Name,
+			City:      a.City,
+			Country:   strings.ToUpper(a.Country),
+			TimeZone:  loc.String(),
+			Location:  loc,
+			Latitude:  a.Latitude,
+			Longitude: a.Longitude,
+		}
+	}
+	for _, m := range payload.Metros {
+		code := strings.ToUpper(m.Code)
+		if _, ok := reg.airports[code]; ok {
+			return nil, fmt.Errorf("%w: metro code %s is also an airport code", ErrInvalidDataset, code)
+		}
+		airports := make([]string, 0, len(m.Airports))
+		for _, a := range m.Airports {
+			a = strings.ToUpper(a)
+			if _, ok := reg.airports[a]; !ok {
+				return nil, fmt.Errorf("%w: metro %s lists unknown airport %s", ErrInvalidDataset, code, a)
+			}
+			airports = append(airports, a)
+		}
+		reg.metros[code] = airports
+	}
+
+	return reg, nil
+}
+
+func (d *Registry) Airport(code string) (Airport, bool) {
+	a, ok := d.airports[strings.ToUpper(code)]
+	return a, ok
+}
+
+func (d *Registry) Resolve(code string) []string {
+	code = strings.ToUpper(code)
+	if airports, ok := d.metros[code]; ok {
+		return append([]string(nil), airports...)
+	}
+	if _, ok := d.airports[code]; ok {
+		return []string{code}
+	}
+	return nil
+}
+
+func (d *Registry) Nearby(code string, radiusKm float64) []string {
+	origin, ok := d.Airport(code)
+	if !ok || radiusKm <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		code     string
+		distance float64
+	}
+	candidates := make([]candidate, 0)
+	for _, a := range d.airports {
+		if a.Code == origin.Code {
+			continue
+		}
+		if distance := DistanceKm(origin, a); distance <= radiusKm {
+			candidates = append(candidates, candidate{code: a.Code, distance: distance})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].code < candidates[j].code
+	})
+
+	nearby := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		nearby = append(nearby, c.code)
+	}
+	return nearby
+}
@@ -0,0 +1,43 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrUnsupported = errors.New("unsupported currency")
+
+type Rates interface {
+	// Rate returns how many units of to one unit of from is worth.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Exponent returns the number of minor-unit digits used for amounts in the
+// given currency. Rupiah and yen amounts are kept in whole units.
+func Exponent(code string) int {
+	switch strings.ToUpper(code) {
+	case "IDR", "JPY", "KRW", "VND":
+		return 0
+	default:
+		return 2
+	}
+}
+
+// Convert converts an amount expressed in minor units of from into minor
+// units of to using rate.
+func Convert(amount int, from, to string, rate float64) int {
+	major := float64(amount) / math.Pow10(Exponent(from))
+	return int(math.Round(major * rate * math.Pow10(Exponent(to))))
+}
+
+// ToMinor converts a major-unit value (e.g. 12.5 USD) into minor units.
+func ToMinor(value float64, code string) int {
+	return int(math.Round(value * math.Pow10(Exponent(code))))
+}
+
+// ToMajor converts minor units back into a major-unit value.
+func ToMajor(amount int, code string) float64 {
+	return float64(amount) / math.Pow10(Exponent(code))
+}
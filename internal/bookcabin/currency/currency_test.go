@@ -0,0 +1,82 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExponent(t *testing.T) {
+	tests := map[string]int{"IDR": 0, "jpy": 0, "KRW": 0, "VND": 0, "USD": 2, "SGD": 2, "EUR": 2, "XYZ": 2}
+	for code, want := range tests {
+		if got := Exponent(code); got != want {
+			t.Fatalf("Exponent(%q): expected %d, got %d", code, want, got)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int
+		from, to string
+		rate     float64
+		want     int
+	}{
+		{name: "rupiah to cents", amount: 1250000, from: "IDR", to: "USD", rate: 0.0000625, want: 7813},
+		{name: "cents to rupiah", amount: 7813, from: "USD", to: "IDR", rate: 16000, want: 1250080},
+		{name: "between zero decimal currencies", amount: 1250000, from: "IDR", to: "JPY", rate: 0.0095, want: 11875},
+		{name: "between two decimal currencies", amount: 1000, from: "USD", to: "SGD", rate: 1.3328, want: 1333},
+		{name: "rounds half away from zero", amount: 10, from: "IDR", to: "IDR", rate: 0.25, want: 3},
+		{name: "identity", amount: 780000, from: "IDR", to: "IDR", rate: 1, want: 780000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Convert(tt.amount, tt.from, tt.to, tt.rate); got != tt.want {
+				t.Fatalf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMinorMajor(t *testing.T) {
+	if got := ToMinor(12.345, "USD"); got != 1235 {
+		t.Fatalf("ToMinor USD: expected 1235, got %d", got)
+	}
+	if got := ToMinor(1250000.4, "IDR"); got != 1250000 {
+		t.Fatalf("ToMinor IDR: expected 1250000, got %d", got)
+	}
+	if got := ToMajor(7813, "USD"); math.Abs(got-78.13) > 1e-9 {
+		t.Fatalf("ToMajor USD: expected 78.13, got %v", got)
+	}
+	if got := ToMajor(11875, "JPY"); got != 11875 {
+		t.Fatalf("ToMajor JPY: expected 11875, got %v", got)
+	}
+}
+
+func TestFileRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	content := `{"base": "idr", "rates": {"USD": 0.0000625, "SGD": 0.0000833, "XXX": 0}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write rates: %v", err)
+	}
+	rates, err := NewFileRates(path)
+	if err != nil {
+		t.Fatalf("NewFileRates: %v", err)
+	}
+
+	if got, err := rates.Rate(context.Background(), "IDR", "usd"); err != nil || got != 0.0000625 {
+		t.Fatalf("IDR to USD: expected 0.0000625, got %v (%v)", got, err)
+	}
+	if got, err := rates.Rate(context.Background(), "USD", "SGD"); err != nil || math.Abs(got-1.3328) > 1e-9 {
+		t.Fatalf("USD to SGD: expected a cross rate of 1.3328, got %v (%v)", got, err)
+	}
+	for _, code := range []string{"XXX", "GBP"} {
+		if _, err := rates.Rate(context.Background(), "IDR", code); !errors.Is(err, ErrUnsupported) {
+			t.Fatalf("IDR to %s: expected ErrUnsupported, got %v", code, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type FileRates struct {
+	base  string
+	rates map[string]float64
+}
+
+func NewFileRates(path string) (*FileRates, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("exchange rates read file: %w", err)
+	}
+
+	var payload struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("exchange rates decode: %w", err)
+	}
+
+	base := strings.ToUpper(payload.Base)
+	rates := make(map[string]float64, len(payload.Rates)+1)
+	for code, rate := range payload.Rates {
+		if rate > 0 {
+			rates[strings.ToUpper(code)] = rate
+		}
+	}
+	rates[base] = 1
+
+	return &FileRates{base: base, rates: rates}, nil
+}
+
+func (f *FileRates) Rate(_ context.Context, from, to string) (float64, error) {
+	fromRate, ok := f.rates[strings.ToUpper(from)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupported, from)
+	}
+	toRate, ok := f.rates[strings.ToUpper(to)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupported, to)
+	}
+	return toRate / fromRate, nil
+}
@@ -2,9 +2,14 @@ package entity
 
 import "time"
 
+// Airline is normalized against the airline registry; ICAO, Type and
+// Alliance stay empty for carriers the registry does not know.
 type Airline struct {
-	Name string
-	Code string
+	Name     string
+	Code     string
+	ICAO     string
+	Type     string
+	Alliance string
 }
 
 type FlightPoint struct {
@@ -13,30 +18,99 @@ type FlightPoint struct {
 	Time    time.Time
 }
 
+// Price amounts are per passenger unless stated otherwise; Total covers
+// every passenger in the search.
 type Price struct {
-	Amount   int
-	Currency string
+	Amount     int
+	Total      int
+	Passengers int
+	Currency   string
+	Breakdown  *PriceBreakdown
+	Fares      []PassengerFare
 }
 
+type PriceBreakdown struct {
+	BaseFare int
+	Taxes    int
+	Fees     int
+}
+
+// Amenities are normalized to this vocabulary; anything else a provider
+// lists is kept lowercased.
+const (
+	AmenityWifi          = "wifi"
+	AmenityMeal          = "meal"
+	AmenityEntertainment = "entertainment"
+	AmenityPower         = "power"
+)
+
 type Baggage struct {
-	CarryOn string
-	Checked string
+	CarryOn BaggageAllowance
+	Checked BaggageAllowance
 }
 
-type Flight struct {
-	ID             string
-	Provider       string
-	Airline        Airline
+// BaggageAllowance is what a provider's baggage text says. Included is false
+// for baggage sold for a fee; Pieces and WeightKg are zero when the provider
+// does not state them. Description keeps the provider's wording.
+type BaggageAllowance struct {
+	Included    bool
+	Pieces      int
+	WeightKg    int
+	Description string
+}
+
+type Segment struct {
 	FlightNumber   string
 	Departure      FlightPoint
 	Arrival        FlightPoint
 	DurationMinute int
-	Stops          int
+	LayoverMinute  int
+}
+
+// Airline and FlightNumber are the marketing carrier selling the flight; the
+// operating ones are the carrier flying it and only differ for codeshares.
+type Flight struct {
+	ID                    string
+	Provider              string
+	Airline               Airline
+	FlightNumber          string
+	OperatingAirline      Airline
+	OperatingFlightNumber string
+	Departure             FlightPoint
+	Arrival               FlightPoint
+	DurationMinute        int
+	Stops                 int
+	Segments              []Segment
+	Price                 Price
+	AvailableSeats        int
+	CabinClass            string
+	FareClass             string
+	Aircraft              *string
+	Amenities             []string
+	Baggage               Baggage
+	BestValueScore        float64
+	ScoreFactors          []ScoreFactor
+	Offers                []Offer
+}
+
+// ScoreFactor is one factor of a best value score: Score runs from 0 (best
+// in the result set) to 1 (worst) and Weight is its normalized share.
+type ScoreFactor struct {
+	Name   string
+	Weight float64
+	Score  float64
+}
+
+// Offer is another way to book the same operating flight: a codeshare sold
+// under a different flight number, or the same flight from another provider.
+type Offer struct {
+	FlightID       string
+	Provider       string
+	Airline        Airline
+	FlightNumber   string
 	Price          Price
 	AvailableSeats int
-	CabinClass     string
-	Aircraft       *string
-	Amenities      []string
 	Baggage        Baggage
-	BestValueScore float64
+	CabinClass     string
+	FareClass      string
 }
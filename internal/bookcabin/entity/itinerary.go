@@ -0,0 +1,12 @@
+package entity
+
+type Itinerary struct {
+	ID               string
+	Outbound         Flight
+	Return           Flight
+	Price            Price
+	DurationMinute   int
+	TurnaroundMinute int
+	BestValueScore   float64
+	ScoreFactors     []ScoreFactor
+}
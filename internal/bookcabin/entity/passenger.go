@@ -0,0 +1,29 @@
+package entity
+
+const (
+	PassengerAdult  = "adult"
+	PassengerChild  = "child"
+	PassengerInfant = "infant"
+)
+
+type Passengers struct {
+	Adults   int
+	Children int
+	Infants  int
+}
+
+// Seats counts the passengers that occupy a seat; infants travel on a lap.
+func (p Passengers) Seats() int {
+	return p.Adults + p.Children
+}
+
+func (p Passengers) Total() int {
+	return p.Adults + p.Children + p.Infants
+}
+
+type PassengerFare struct {
+	Type   string
+	Count  int
+	Amount int
+	Total  int
+}
@@ -3,16 +3,35 @@ package inbound
 import (
 	"context"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
 	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgrouter"
 )
 
 type uc interface {
 	Flights(ctx context.Context, in usecase.FlightsInput) (*usecase.FlightsOutput, error)
+	FlightsPage(ctx context.Context, in usecase.FlightsPageInput) (*usecase.FlightsOutput, error)
+	FlightsStream(ctx context.Context, in usecase.FlightsInput, emit func(usecase.FlightsStreamEvent)) (*usecase.FlightsOutput, error)
+	MultiCity(ctx context.Context, in usecase.MultiCityInput) (*usecase.MultiCityOutput, error)
+	FareCalendar(ctx context.Context, in usecase.FareCalendarInput) (*usecase.FareCalendarOutput, error)
+	CircuitBreakers(ctx context.Context) []provider.BreakerStatus
+	Airlines(ctx context.Context) []airline.Airline
+	CreateSearch(ctx context.Context, in usecase.FlightsInput) (*usecase.SearchJob, error)
+	SearchJob(ctx context.Context, id string) (*usecase.SearchJob, error)
+	CancelSearch(ctx context.Context, id string) (*usecase.SearchJob, error)
 }
 
 func RegisterHTTPEndpoint(r *pkgrouter.Router, uc uc) {
 	end := &HTTPEndpoint{uc: uc}
 
 	r.GET("/flights", end.Flights)
+	r.GETStream("/flights/stream", end.FlightsStream)
+	r.POST("/flights/multi-city", end.MultiCity)
+	r.GET("/flights/calendar", end.FareCalendar)
+	r.GET("/providers/circuit-breakers", end.CircuitBreakers)
+	r.GET("/airlines", end.Airlines)
+	r.POST("/searches", end.CreateSearch)
+	r.GET("/searches/:id", end.SearchJob)
+	r.DELETE("/searches/:id", end.CancelSearch)
 }
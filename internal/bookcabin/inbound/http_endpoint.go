@@ -2,9 +2,15 @@ package inbound
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgrouter"
 )
 
 type HTTPEndpoint struct {
@@ -12,60 +18,509 @@ type HTTPEndpoint struct {
 }
 
 func (h *HTTPEndpoint) Flights(ctx context.Context, r *http.Request) (any, error) {
+	output, err := h.searchFlights(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	flights := mapFlightResponses(output.Flights)
+	returnFlights := mapFlightResponses(output.ReturnFlights)
+
+	return FlightsResponse{
+		SearchCriteria: mapSearchCriteria(output.SearchCriteria),
+		Metadata:       mapMetadata(output.Metadata),
+		Flights:        flights,
+		ReturnFlights:  returnFlights,
+		Itineraries:    mapItineraryResponses(output.Itineraries),
+		Facets:         mapFacets(output.Facets),
+		ReturnFacets:   mapReturnFacets(output.ReturnFacets),
+	}, nil
+}
+
+// searchFlights runs a new search, or serves the next page of an earlier one
+// when the request carries a cursor.
+func (h *HTTPEndpoint) searchFlights(ctx context.Context, r *http.Request) (*usecase.FlightsOutput, error) {
+	q := r.URL.Query()
+	if q.Get("cursor") != "" {
+		input, err := parsePageFlightsInput(q)
+		if err != nil {
+			return nil, err
+		}
+		return h.uc.FlightsPage(ctx, input)
+	}
+
+	input, err := parseFlightsQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	return h.uc.Flights(ctx, input)
+}
+
+func (h *HTTPEndpoint) FlightsStream(ctx context.Context, r *http.Request, stream pkgrouter.StreamWriter) error {
 	input, err := parseFlightsInput(r)
+	if err != nil {
+		return err
+	}
+
+	// A failed write means the client went away; the request context is then
+	// canceled and the search stops on its own.
+	output, err := h.uc.FlightsStream(ctx, input, func(event usecase.FlightsStreamEvent) {
+		_ = stream.Send("flights", mapStreamEvent(event))
+	})
+	if err != nil {
+		return err
+	}
+
+	return stream.Send("summary", FlightsStreamSummaryResponse{
+		SearchCriteria: mapSearchCriteria(output.SearchCriteria),
+		Metadata:       mapMetadata(output.Metadata),
+	})
+}
+
+func mapStreamEvent(event usecase.FlightsStreamEvent) FlightsStreamEventResponse {
+	resp := FlightsStreamEventResponse{
+		Leg:         event.Leg,
+		Flights:     mapFlightResponses(event.Flights),
+		ReplacedIDs: event.ReplacedIDs,
+	}
+	if event.Provider != nil {
+		status := mapProviderStatuses([]usecase.ProviderStatus{*event.Provider})[0]
+		resp.Provider = &status
+	}
+	return resp
+}
+
+func (h *HTTPEndpoint) MultiCity(ctx context.Context, r *http.Request) (any, error) {
+	input, err := parseMultiCityInput(r)
 	if err != nil {
 		return nil, err
 	}
 
-	output, err := h.uc.Flights(ctx, input)
+	output, err := h.uc.MultiCity(ctx, input)
 	if err != nil {
 		return nil, err
 	}
 
-	flights := mapFlightResponses(output.Flights)
-	returnFlights := mapFlightResponses(output.ReturnFlights)
+	legs := make([]MultiCityLegResponse, 0, len(output.Legs))
+	for _, leg := range output.Legs {
+		legs = append(legs, MultiCityLegResponse{
+			SearchCriteria: mapSearchCriteria(leg.SearchCriteria),
+			Metadata:       mapMetadata(leg.Metadata),
+			Flights:        mapFlightResponses(leg.Flights),
+		})
+	}
 
-	return FlightsResponse{
-		SearchCriteria: SearchCriteriaResponse{
-			Origin:        output.SearchCriteria.Origin,
-			Destination:   output.SearchCriteria.Destination,
-			DepartureDate: output.SearchCriteria.DepartureDate,
-			ReturnDate:    output.SearchCriteria.ReturnDate,
-			Passengers:    output.SearchCriteria.Passengers,
-			CabinClass:    output.SearchCriteria.CabinClass,
-		},
-		Metadata: MetadataResponse{
+	return MultiCityResponse{
+		Metadata: MultiCityMetadataResponse{
+			TotalLegs:          output.Metadata.TotalLegs,
 			TotalResults:       output.Metadata.TotalResults,
-			ProvidersQueried:   output.Metadata.ProvidersQueried,
+			Complete:           output.Metadata.Complete,
+			EmptyLegs:          output.Metadata.EmptyLegs,
+			TotalPriceRange:    mapPriceRange(output.Metadata.PriceRange),
 			ProvidersSucceeded: output.Metadata.ProvidersSucceeded,
-			ProvidersFailed:    output.Metadata.ProvidersFailed,
 			SearchTimeMs:       output.Metadata.SearchTimeMs,
-			CacheHit:           output.Metadata.CacheHit,
 		},
-		Flights:       flights,
-		ReturnFlights: returnFlights,
+		Legs: legs,
 	}, nil
 }
 
+func (h *HTTPEndpoint) FareCalendar(ctx context.Context, r *http.Request) (any, error) {
+	input, err := parseFareCalendarInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := h.uc.FareCalendar(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]FareCalendarDayResponse, 0, len(output.Days))
+	for _, day := range output.Days {
+		days = append(days, mapFareCalendarDay(day))
+	}
+
+	meta := output.Metadata
+	return FareCalendarResponse{
+		SearchCriteria: mapSearchCriteria(output.SearchCriteria),
+		Metadata: FareCalendarMetadataResponse{
+			From:            meta.From,
+			To:              meta.To,
+			TotalDays:       meta.TotalDays,
+			DaysWithResults: meta.DaysWithResults,
+			CheapestDate:    meta.CheapestDate,
+			CacheHits:       meta.CacheHits,
+			Partial:         meta.Partial,
+			SearchTimeMs:    meta.SearchTimeMs,
+		},
+		Days: days,
+	}, nil
+}
+
+func mapFareCalendarDay(day usecase.FareCalendarDay) FareCalendarDayResponse {
+	resp := FareCalendarDayResponse{
+		Date:     day.Date,
+		Results:  day.Results,
+		CacheHit: day.CacheHit,
+		Partial:  day.Partial,
+	}
+	if day.Results == 0 {
+		return resp
+	}
+
+	flightID := day.CheapestFlight
+	resp.CheapestFlight = &flightID
+	resp.LowestPrice = &LowestPriceResponse{
+		Amount:         currency.ToMajor(day.LowestPrice, day.Currency),
+		Total:          currency.ToMajor(day.LowestTotal, day.Currency),
+		Currency:       day.Currency,
+		Formatted:      formatMoney(day.LowestPrice, day.Currency),
+		FormattedTotal: formatMoney(day.LowestTotal, day.Currency),
+	}
+	if day.CheapestAirline != nil {
+		cheapest := mapAirline(*day.CheapestAirline)
+		resp.CheapestAirline = &cheapest
+	}
+	return resp
+}
+
+func (h *HTTPEndpoint) CircuitBreakers(ctx context.Context, _ *http.Request) (any, error) {
+	statuses := h.uc.CircuitBreakers(ctx)
+	breakers := make([]CircuitBreakerResponse, 0, len(statuses))
+	for _, status := range statuses {
+		breakers = append(breakers, CircuitBreakerResponse{
+			Provider:            status.Provider,
+			State:               string(status.State),
+			ConsecutiveFailures: status.ConsecutiveFailures,
+			FailureThreshold:    status.FailureThreshold,
+			CoolDownMs:          status.CoolDown.Milliseconds(),
+			OpenedAt:            formatOptionalTime(status.OpenedAt),
+			RetryAt:             formatOptionalTime(status.RetryAt),
+		})
+	}
+	return CircuitBreakersResponse{Breakers: breakers}, nil
+}
+
+func (h *HTTPEndpoint) Airlines(ctx context.Context, _ *http.Request) (any, error) {
+	airlines := h.uc.Airlines(ctx)
+	resp := make([]AirlineDetailResponse, 0, len(airlines))
+	for _, a := range airlines {
+		aliases := a.Aliases
+		if aliases == nil {
+			aliases = []string{}
+		}
+		var alliance *string
+		if a.Alliance != "" {
+			alliance = &a.Alliance
+		}
+		resp = append(resp, AirlineDetailResponse{
+			Code:     a.Code,
+			ICAO:     a.ICAO,
+			Name:     a.Name,
+			Aliases:  aliases,
+			Type:     a.Type,
+			Alliance: alliance,
+		})
+	}
+	return AirlinesResponse{Airlines: resp}, nil
+}
+
+func (h *HTTPEndpoint) CreateSearch(ctx context.Context, r *http.Request) (any, error) {
+	input, err := parseSearchJobInput(r)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := h.uc.CreateSearch(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return SearchJobAcceptedResponse{SearchJobResponse: mapSearchJob(job)}, nil
+}
+
+func (h *HTTPEndpoint) SearchJob(ctx context.Context, _ *http.Request) (any, error) {
+	job, err := h.uc.SearchJob(ctx, pkgrouter.GetParam(ctx, "id"))
+	if err != nil {
+		return nil, err
+	}
+	return mapSearchJob(job), nil
+}
+
+func (h *HTTPEndpoint) CancelSearch(ctx context.Context, _ *http.Request) (any, error) {
+	job, err := h.uc.CancelSearch(ctx, pkgrouter.GetParam(ctx, "id"))
+	if err != nil {
+		return nil, err
+	}
+	return mapSearchJob(job), nil
+}
+
+func mapSearchJob(job *usecase.SearchJob) SearchJobResponse {
+	resp := SearchJobResponse{
+		ID:            job.ID,
+		Status:        string(job.Status),
+		CreatedAt:     job.CreatedAt.Format(time.RFC3339),
+		StartedAt:     formatOptionalTime(job.StartedAt),
+		FinishedAt:    formatOptionalTime(job.FinishedAt),
+		ExpiresAt:     formatOptionalTime(job.ExpiresAt),
+		Providers:     mapProviderStatuses(job.Providers),
+		Flights:       mapFlightResponses(job.Flights),
+		ReturnFlights: mapFlightResponses(job.ReturnFlights),
+	}
+	if job.Err != nil {
+		resp.Error = "search failed"
+		var gerr *pkgerror.Error
+		if errors.As(job.Err, &gerr) {
+			resp.Error = gerr.Msg()
+		}
+	}
+	if job.Output != nil {
+		criteria := mapSearchCriteria(job.Output.SearchCriteria)
+		metadata := mapMetadata(job.Output.Metadata)
+		resp.SearchCriteria = &criteria
+		resp.Metadata = &metadata
+		resp.Itineraries = mapItineraryResponses(job.Output.Itineraries)
+	}
+	return resp
+}
+
+func mapSearchCriteria(criteria usecase.SearchCriteria) SearchCriteriaResponse {
+	return SearchCriteriaResponse{
+		Origin:              criteria.Origin,
+		Destination:         criteria.Destination,
+		OriginAirports:      criteria.OriginAirports,
+		DestinationAirports: criteria.DestinationAirports,
+		DepartureDate:       criteria.DepartureDate,
+		ReturnDate:          criteria.ReturnDate,
+		Passengers:          criteria.Passengers.Total(),
+		PassengerMix: PassengerMixResponse{
+			Adults:   criteria.Passengers.Adults,
+			Children: criteria.Passengers.Children,
+			Infants:  criteria.Passengers.Infants,
+		},
+		CabinClass: criteria.CabinClass,
+		Currency:   criteria.Currency,
+	}
+}
+
+func mapMetadata(meta usecase.SearchMetadata) MetadataResponse {
+	return MetadataResponse{
+		TotalResults:       meta.TotalResults,
+		TotalBeforeFilters: meta.TotalBeforeFilters,
+		ProvidersQueried:   meta.ProvidersQueried,
+		ProvidersSucceeded: meta.ProvidersSucceeded,
+		ProvidersFailed:    meta.ProvidersFailed,
+		ProvidersPending:   meta.ProvidersPending,
+		FailedProviders:    mapFailedProviders(meta.FailedProviders),
+		PendingProviders:   meta.PendingProviders,
+		Providers:          mapProviderStatuses(meta.Providers),
+		SearchTimeMs:       meta.SearchTimeMs,
+		CacheHit:           meta.CacheHit,
+		Partial:            meta.Partial,
+		Offset:             meta.Offset,
+		Limit:              meta.Limit,
+		NextCursor:         meta.NextCursor,
+		NextReturnCursor:   meta.NextReturnCursor,
+	}
+}
+
+func mapBaggage(baggage entity.Baggage) BaggageResponse {
+	return BaggageResponse{
+		CarryOn:          baggage.CarryOn.Description,
+		Checked:          baggage.Checked.Description,
+		CarryOnAllowance: mapBaggageAllowance(baggage.CarryOn),
+		CheckedAllowance: mapBaggageAllowance(baggage.Checked),
+	}
+}
+
+// mapBaggageAllowance leaves pieces and weight null when the provider does not
+// state them.
+func mapBaggageAllowance(allowance entity.BaggageAllowance) BaggageAllowanceResponse {
+	resp := BaggageAllowanceResponse{Included: allowance.Included}
+	if allowance.Pieces > 0 {
+		resp.Pieces = &allowance.Pieces
+	}
+	if allowance.WeightKg > 0 {
+		resp.WeightKg = &allowance.WeightKg
+	}
+	return resp
+}
+
+func mapFacets(facets usecase.Facets) FacetsResponse {
+	resp := FacetsResponse{
+		Total:          facets.Total,
+		Airlines:       mapFacetBuckets(facets.Airlines, facets.Currency),
+		Stops:          mapFacetBuckets(facets.Stops, facets.Currency),
+		DepartureTimes: mapFacetBuckets(facets.DepartureTimes, facets.Currency),
+		Price:          mapPriceRange(facets.PriceRange),
+		PriceHistogram: make([]PriceBucketResponse, 0, len(facets.PriceHistogram)),
+	}
+	for _, b := range facets.PriceHistogram {
+		resp.PriceHistogram = append(resp.PriceHistogram, PriceBucketResponse{
+			Min:          currency.ToMajor(b.Min, facets.Currency),
+			Max:          currency.ToMajor(b.Max, facets.Currency),
+			Count:        b.Count,
+			FormattedMin: formatMoney(b.Min, facets.Currency),
+			FormattedMax: formatMoney(b.Max, facets.Currency),
+		})
+	}
+	if facets.Duration != nil {
+		resp.Duration = &DurationRangeResponse{
+			Min: DurationResponse{TotalMinutes: facets.Duration.Min, Formatted: formatDuration(facets.Duration.Min)},
+			Max: DurationResponse{TotalMinutes: facets.Duration.Max, Formatted: formatDuration(facets.Duration.Max)},
+		}
+	}
+	return resp
+}
+
+func mapReturnFacets(facets *usecase.Facets) *FacetsResponse {
+	if facets == nil {
+		return nil
+	}
+	resp := mapFacets(*facets)
+	return &resp
+}
+
+func mapFacetBuckets(buckets []usecase.FacetBucket, code string) []FacetBucketResponse {
+	resp := make([]FacetBucketResponse, 0, len(buckets))
+	for _, b := range buckets {
+		resp = append(resp, FacetBucketResponse{
+			Value:             b.Value,
+			Label:             b.Label,
+			Count:             b.Count,
+			MinPrice:          currency.ToMajor(b.MinPrice, code),
+			FormattedMinPrice: formatMoney(b.MinPrice, code),
+		})
+	}
+	return resp
+}
+
+func mapProviderStatuses(statuses []usecase.ProviderStatus) []ProviderStatusResponse {
+	resp := make([]ProviderStatusResponse, 0, len(statuses))
+	for _, status := range statuses {
+		resp = append(resp, ProviderStatusResponse{
+			Name:       status.Name,
+			Leg:        status.Leg,
+			Route:      status.Route,
+			Outcome:    status.Outcome,
+			ErrorClass: status.ErrorClass,
+			Attempts:   status.Attempts,
+			Hedged:     status.Hedged,
+			LatencyMs:  status.LatencyMs,
+			Results:    status.Results,
+		})
+	}
+	return resp
+}
+
+func mapFailedProviders(failed []usecase.FailedProvider) []FailedProviderResponse {
+	resp := make([]FailedProviderResponse, 0, len(failed))
+	for _, f := range failed {
+		resp = append(resp, FailedProviderResponse{Name: f.Name, Reason: f.Reason})
+	}
+	return resp
+}
+
+func mapItineraryResponses(itineraries []entity.Itinerary) []ItineraryResponse {
+	resp := make([]ItineraryResponse, 0, len(itineraries))
+	for _, itinerary := range itineraries {
+		resp = append(resp, ItineraryResponse{
+			ID:             itinerary.ID,
+			Outbound:       mapFlightResponse(itinerary.Outbound),
+			Return:         mapFlightResponse(itinerary.Return),
+			TotalPrice:     mapPrice(itinerary.Price),
+			TotalDuration:  DurationResponse{TotalMinutes: itinerary.DurationMinute, Formatted: formatDuration(itinerary.DurationMinute)},
+			Turnaround:     DurationResponse{TotalMinutes: itinerary.TurnaroundMinute, Formatted: formatDuration(itinerary.TurnaroundMinute)},
+			BestValueScore: itinerary.BestValueScore,
+			ScoreBreakdown: mapScoreFactors(itinerary.ScoreFactors),
+		})
+	}
+	return resp
+}
+
 func mapFlightResponses(flights []entity.Flight) []FlightResponse {
 	resp := make([]FlightResponse, 0, len(flights))
 	for _, flight := range flights {
-		resp = append(resp, FlightResponse{
-			ID:             flight.ID,
-			Provider:       flight.Provider,
-			Airline:        AirlineResponse{Name: flight.Airline.Name, Code: flight.Airline.Code},
-			FlightNumber:   flight.FlightNumber,
-			Departure:      mapFlightPoint(flight.Departure),
-			Arrival:        mapFlightPoint(flight.Arrival),
-			Duration:       DurationResponse{TotalMinutes: flight.DurationMinute, Formatted: formatDuration(flight.DurationMinute)},
-			Stops:          flight.Stops,
-			Price:          PriceResponse{Amount: flight.Price.Amount, Currency: flight.Price.Currency, Formatted: formatIDR(flight.Price.Amount)},
-			AvailableSeats: flight.AvailableSeats,
-			CabinClass:     flight.CabinClass,
-			Aircraft:       flight.Aircraft,
-			Amenities:      append([]string{}, flight.Amenities...),
-			Baggage:        BaggageResponse{CarryOn: flight.Baggage.CarryOn, Checked: flight.Baggage.Checked},
+		resp = append(resp, mapFlightResponse(flight))
+	}
+	return resp
+}
+
+func mapScoreFactors(factors []entity.ScoreFactor) []ScoreFactorResponse {
+	resp := make([]ScoreFactorResponse, 0, len(factors))
+	for _, f := range factors {
+		resp = append(resp, ScoreFactorResponse{
+			Factor:       f.Name,
+			Weight:       f.Weight,
+			Score:        f.Score,
+			Contribution: f.Weight * f.Score,
 		})
 	}
 	return resp
 }
+
+func mapAirline(a entity.Airline) AirlineResponse {
+	return AirlineResponse{Name: a.Name, Code: a.Code, ICAO: a.ICAO, Type: a.Type, Alliance: a.Alliance}
+}
+
+func mapFlightResponse(flight entity.Flight) FlightResponse {
+	resp := FlightResponse{
+		ID:             flight.ID,
+		Provider:       flight.Provider,
+		Airline:        mapAirline(flight.Airline),
+		FlightNumber:   flight.FlightNumber,
+		Departure:      mapFlightPoint(flight.Departure),
+		Arrival:        mapFlightPoint(flight.Arrival),
+		Duration:       DurationResponse{TotalMinutes: flight.DurationMinute, Formatted: formatDuration(flight.DurationMinute)},
+		Stops:          flight.Stops,
+		Segments:       mapSegmentResponses(flight.Segments),
+		Price:          mapPrice(flight.Price),
+		AvailableSeats: flight.AvailableSeats,
+		CabinClass:     flight.CabinClass,
+		FareClass:      flight.FareClass,
+		Aircraft:       flight.Aircraft,
+		Amenities:      append([]string{}, flight.Amenities...),
+		Baggage:        mapBaggage(flight.Baggage),
+		BestValueScore: flight.BestValueScore,
+		ScoreBreakdown: mapScoreFactors(flight.ScoreFactors),
+		Offers:         make([]OfferResponse, 0, len(flight.Offers)),
+	}
+	if flight.OperatingFlightNumber != "" && flight.OperatingFlightNumber != flight.FlightNumber {
+		operating := mapAirline(flight.OperatingAirline)
+		resp.OperatingAirline = &operating
+		resp.OperatingFlightNumber = flight.OperatingFlightNumber
+	}
+	for _, offer := range flight.Offers {
+		resp.Offers = append(resp.Offers, OfferResponse{
+			FlightID:       offer.FlightID,
+			Provider:       offer.Provider,
+			Airline:        mapAirline(offer.Airline),
+			FlightNumber:   offer.FlightNumber,
+			Price:          mapPrice(offer.Price),
+			AvailableSeats: offer.AvailableSeats,
+			Baggage:        mapBaggage(offer.Baggage),
+			CabinClass:     offer.CabinClass,
+			FareClass:      offer.FareClass,
+		})
+	}
+	return resp
+}
+
+func mapSegmentResponses(segments []entity.Segment) []SegmentResponse {
+	resp := make([]SegmentResponse, 0, len(segments))
+	for _, segment := range segments {
+		item := SegmentResponse{
+			FlightNumber: segment.FlightNumber,
+			Departure:    mapSegmentPoint(segment.Departure),
+			Arrival:      mapSegmentPoint(segment.Arrival),
+		}
+		if segment.DurationMinute > 0 {
+			item.Duration = &DurationResponse{TotalMinutes: segment.DurationMinute, Formatted: formatDuration(segment.DurationMinute)}
+		}
+		if segment.LayoverMinute > 0 {
+			item.Layover = &DurationResponse{TotalMinutes: segment.LayoverMinute, Formatted: formatDuration(segment.LayoverMinute)}
+		}
+		resp = append(resp, item)
+	}
+	return resp
+}
@@ -1,27 +1,104 @@
 package inbound
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
 	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
 )
 
+const (
+	minMultiCityLegs = 2
+	maxMultiCityLegs = 6
+
+	defaultMinTurnaroundMinute = 120
+	defaultPairingLimit        = 10
+	maxPairingLimit            = 50
+
+	maxSeatedPassengers = 9
+
+	defaultFlexDays = 3
+	maxFlexDays     = 7
+
+	maxAirportRadiusKm = 300
+
+	maxPageLimit = 100
+)
+
 func parseFlightsInput(r *http.Request) (usecase.FlightsInput, error) {
-	q := r.URL.Query()
+	return parseFlightsQuery(r.URL.Query())
+}
 
-	origin := strings.TrimSpace(q.Get("origin"))
-	destination := strings.TrimSpace(q.Get("destination"))
+// parseSearchJobInput reads a JSON object carrying the same keys as the
+// /flights query string.
+func parseSearchJobInput(r *http.Request) (usecase.FlightsInput, error) {
+	var req map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return usecase.FlightsInput{}, pkgerror.NewInvalidFormat()
+	}
+	values, err := valuesFromMap(req)
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+	return parseFlightsQuery(values)
+}
+
+func parseFareCalendarInput(r *http.Request) (usecase.FareCalendarInput, error) {
+	search, err := parseFlightsInput(r)
+	if err != nil {
+		return usecase.FareCalendarInput{}, err
+	}
+
+	flexDays := defaultFlexDays
+	if raw := strings.TrimSpace(firstNotEmpty(r.URL.Query().Get("flex_days"), r.URL.Query().Get("flexDays"))); raw != "" {
+		flexDays, err = strconv.Atoi(raw)
+		if err != nil || flexDays < 0 || flexDays > maxFlexDays {
+			msg := fmt.Sprintf("flex_days must be between 0 and %d", maxFlexDays)
+			return usecase.FareCalendarInput{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+		}
+	}
+
+	return usecase.FareCalendarInput{Search: search, FlexDays: flexDays}, nil
+}
+
+func parseFlightsQuery(q url.Values) (usecase.FlightsInput, error) {
+	origin, err := parseAirportCodes(q.Get("origin"), "invalid origin")
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+	destination, err := parseAirportCodes(q.Get("destination"), "invalid destination")
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
 	if origin == "" || destination == "" {
 		return usecase.FlightsInput{}, pkgerror.NewBusiness("origin and destination are required", pkgerror.CodeInvalidInput)
 	}
 
+	var originRadius, destinationRadius *float64
+	if err := parseFloatFilter(q, "origin_radius_km", "originRadiusKm", "invalid origin_radius_km", &originRadius); err != nil {
+		return usecase.FlightsInput{}, err
+	}
+	if err := parseFloatFilter(q, "destination_radius_km", "destinationRadiusKm", "invalid destination_radius_km", &destinationRadius); err != nil {
+		return usecase.FlightsInput{}, err
+	}
+	for _, radius := range []*float64{originRadius, destinationRadius} {
+		if radius != nil && (*radius < 0 || *radius > maxAirportRadiusKm) {
+			msg := fmt.Sprintf("airport radius must be between 0 and %d km", maxAirportRadiusKm)
+			return usecase.FlightsInput{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+		}
+	}
+
 	departureDateStr := strings.TrimSpace(firstNotEmpty(q.Get("departureDate"), q.Get("departure_date")))
 	if departureDateStr == "" {
 		return usecase.FlightsInput{}, pkgerror.NewBusiness("departureDate is required", pkgerror.CodeInvalidInput)
@@ -41,13 +118,9 @@ func parseFlightsInput(r *http.Request) (usecase.FlightsInput, error) {
 		returnDate = &parsed
 	}
 
-	passengers := 1
-	if value := strings.TrimSpace(q.Get("passengers")); value != "" {
-		parsed, err := strconv.Atoi(value)
-		if err != nil || parsed <= 0 {
-			return usecase.FlightsInput{}, pkgerror.NewBusiness("invalid passengers", pkgerror.CodeInvalidInput)
-		}
-		passengers = parsed
+	passengers, err := parsePassengers(q)
+	if err != nil {
+		return usecase.FlightsInput{}, err
 	}
 
 	cabinClass := strings.TrimSpace(firstNotEmpty(q.Get("cabinClass"), q.Get("cabin_class")))
@@ -55,14 +128,44 @@ func parseFlightsInput(r *http.Request) (usecase.FlightsInput, error) {
 		cabinClass = "economy"
 	}
 
-	filters, err := parseFlightFilters(q, departureDate)
+	currencyCode, err := parseCurrency(q.Get("currency"))
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+
+	filters, err := parseFlightFilters(q)
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+
+	sortOpt, err := parseSort(q.Get("sort"), q.Get("order"))
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+
+	pairing, err := parsePairingOption(q, returnDate != nil)
+	if err != nil {
+		return usecase.FlightsInput{}, err
+	}
+
+	scoring, err := parseScoreOptions(q)
 	if err != nil {
 		return usecase.FlightsInput{}, err
 	}
 
-	sortOpt := usecase.SortOption{
-		Field: strings.TrimSpace(q.Get("sort")),
-		Order: strings.TrimSpace(q.Get("order")),
+	var dedup string
+	switch mode := strings.ToLower(strings.TrimSpace(q.Get("dedup"))); mode {
+	case "", usecase.DedupGrouped:
+		dedup = usecase.DedupGrouped
+	case usecase.DedupCheapest, usecase.DedupOff:
+		dedup = mode
+	default:
+		return usecase.FlightsInput{}, pkgerror.NewBusiness("invalid dedup", pkgerror.CodeInvalidInput)
+	}
+
+	limit, err := parsePageLimit(q)
+	if err != nil {
+		return usecase.FlightsInput{}, err
 	}
 
 	return usecase.FlightsInput{
@@ -74,17 +177,323 @@ func parseFlightsInput(r *http.Request) (usecase.FlightsInput, error) {
 		CabinClass:    strings.ToLower(cabinClass),
 		Filters:       filters,
 		Sort:          sortOpt,
+		Pairing:       pairing,
+		Currency:      currencyCode,
+		Dedup:         dedup,
+		Scoring:       scoring,
+		Limit:         limit,
+
+		OriginRadiusKm:      derefFloat(originRadius),
+		DestinationRadiusKm: derefFloat(destinationRadius),
 	}, nil
 }
 
-func parseFlightFilters(q url.Values, departureDate time.Time) (usecase.FlightFilters, error) {
+// parsePageFlightsInput reads a request for the page behind a cursor. The
+// search parameters live in the cursor, so only limit may change.
+func parsePageFlightsInput(q url.Values) (usecase.FlightsPageInput, error) {
+	limit, err := parsePageLimit(q)
+	if err != nil {
+		return usecase.FlightsPageInput{}, err
+	}
+	return usecase.FlightsPageInput{Cursor: strings.TrimSpace(q.Get("cursor")), Limit: limit}, nil
+}
+
+func parsePageLimit(q url.Values) (int, error) {
+	value := strings.TrimSpace(q.Get("limit"))
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 || limit > maxPageLimit {
+		return 0, pkgerror.NewBusiness(fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), pkgerror.CodeInvalidInput)
+	}
+	return limit, nil
+}
+
+// parseSort reads a multi-key sort such as price:asc,departure:asc,stops.
+// Keys without an order use order, which keeps the single-key sort=price&
+// order=desc form working, and default to ascending.
+func parseSort(value, order string) ([]usecase.SortOption, error) {
+	order = strings.ToLower(strings.TrimSpace(order))
+	if order == "" {
+		order = usecase.SortAsc
+	}
+	if order != usecase.SortAsc && order != usecase.SortDesc {
+		return nil, pkgerror.NewBusiness("invalid order", pkgerror.CodeInvalidInput)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return []usecase.SortOption{{Field: usecase.SortBestValue, Order: order}}, nil
+	}
+
+	keys := make([]usecase.SortOption, 0)
+	for _, part := range strings.Split(value, ",") {
+		field, keyOrder, hasOrder := strings.Cut(strings.ToLower(strings.TrimSpace(part)), ":")
+		field = strings.TrimSpace(field)
+		if !usecase.IsSortField(field) {
+			return nil, pkgerror.NewBusiness(fmt.Sprintf("invalid sort field %q", field), pkgerror.CodeInvalidInput)
+		}
+		key := usecase.SortOption{Field: field, Order: order}
+		if hasOrder {
+			key.Order = strings.TrimSpace(keyOrder)
+			if key.Order != usecase.SortAsc && key.Order != usecase.SortDesc {
+				return nil, pkgerror.NewBusiness(fmt.Sprintf("invalid sort order for %s", field), pkgerror.CodeInvalidInput)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseScoreOptions reads best value weights as factor:weight pairs, e.g.
+// weights=price:0.5,stops:0.3, and the preferred departure time of day. The
+// weights only name the factors to retune; the usecase merges them over the
+// configured ones.
+func parseScoreOptions(q url.Values) (usecase.ScoreOptions, error) {
+	opts := usecase.ScoreOptions{}
+
+	if value := strings.TrimSpace(q.Get("weights")); value != "" {
+		opts.Weights = usecase.ScoreWeights{}
+		for _, pair := range strings.Split(value, ",") {
+			name, rawWeight, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			name = strings.ToLower(strings.TrimSpace(name))
+			weight, err := strconv.ParseFloat(strings.TrimSpace(rawWeight), 64)
+			if !ok || err != nil || !usecase.IsScoreFactor(name) || weight < 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+				return opts, pkgerror.NewBusiness("invalid weights", pkgerror.CodeInvalidInput)
+			}
+			opts.Weights[name] = weight
+		}
+	}
+
+	preferred := strings.ToLower(strings.TrimSpace(firstNotEmpty(q.Get("prefer_time"), q.Get("preferTime"))))
+	if preferred != "" && !usecase.IsTimeOfDay(preferred) {
+		return opts, pkgerror.NewBusiness("invalid prefer_time", pkgerror.CodeInvalidInput)
+	}
+	opts.PreferredTime = preferred
+
+	return opts, nil
+}
+
+// parseAirportCodes accepts a single airport or city code, or a
+// comma-separated list of them, and returns them upper-cased.
+func parseAirportCodes(value, errMsg string) (string, error) {
+	codes := make([]string, 0)
+	for _, code := range strings.Split(value, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if len(code) != 3 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+			return "", pkgerror.NewBusiness(errMsg, pkgerror.CodeInvalidInput)
+		}
+		codes = append(codes, code)
+	}
+	return strings.Join(codes, ","), nil
+}
+
+func derefFloat(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+func parsePassengers(q url.Values) (entity.Passengers, error) {
+	adults, err := parseCount(q, "adults", "passengers", 1)
+	if err != nil {
+		return entity.Passengers{}, pkgerror.NewBusiness("invalid adults", pkgerror.CodeInvalidInput)
+	}
+	children, err := parseCount(q, "children", "childs", 0)
+	if err != nil {
+		return entity.Passengers{}, pkgerror.NewBusiness("invalid children", pkgerror.CodeInvalidInput)
+	}
+	infants, err := parseCount(q, "infants", "infant", 0)
+	if err != nil {
+		return entity.Passengers{}, pkgerror.NewBusiness("invalid infants", pkgerror.CodeInvalidInput)
+	}
+	return newPassengers(adults, children, infants)
+}
+
+func parseCount(q url.Values, key, altKey string, fallback int) (int, error) {
+	value := strings.TrimSpace(firstNotEmpty(q.Get(key), q.Get(altKey)))
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func newPassengers(adults, children, infants int) (entity.Passengers, error) {
+	if adults <= 0 {
+		return entity.Passengers{}, pkgerror.NewBusiness("at least one adult is required", pkgerror.CodeInvalidInput)
+	}
+	if children < 0 {
+		return entity.Passengers{}, pkgerror.NewBusiness("invalid children", pkgerror.CodeInvalidInput)
+	}
+	if infants < 0 {
+		return entity.Passengers{}, pkgerror.NewBusiness("invalid infants", pkgerror.CodeInvalidInput)
+	}
+	if infants > adults {
+		return entity.Passengers{}, pkgerror.NewBusiness("infants must not exceed adults", pkgerror.CodeInvalidInput)
+	}
+	pax := entity.Passengers{Adults: adults, Children: children, Infants: infants}
+	if pax.Seats() > maxSeatedPassengers {
+		msg := fmt.Sprintf("adults and children must not exceed %d", maxSeatedPassengers)
+		return entity.Passengers{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+	}
+	return pax, nil
+}
+
+func parsePairingOption(q url.Values, roundTrip bool) (*usecase.PairingOption, error) {
+	value := strings.TrimSpace(q.Get("pairing"))
+	if value == "" {
+		return nil, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, pkgerror.NewBusiness("invalid pairing", pkgerror.CodeInvalidInput)
+	}
+	if !enabled {
+		return nil, nil
+	}
+	if !roundTrip {
+		return nil, pkgerror.NewBusiness("pairing requires returnDate", pkgerror.CodeInvalidInput)
+	}
+
+	opt := &usecase.PairingOption{
+		MinTurnaroundMinute: defaultMinTurnaroundMinute,
+		Limit:               defaultPairingLimit,
+	}
+	if value := strings.TrimSpace(firstNotEmpty(q.Get("min_turnaround"), q.Get("minTurnaround"))); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return nil, pkgerror.NewBusiness("invalid min_turnaround", pkgerror.CodeInvalidInput)
+		}
+		opt.MinTurnaroundMinute = parsed
+	}
+	if value := strings.TrimSpace(firstNotEmpty(q.Get("pairing_limit"), q.Get("pairingLimit"))); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxPairingLimit {
+			return nil, pkgerror.NewBusiness("invalid pairing_limit", pkgerror.CodeInvalidInput)
+		}
+		opt.Limit = parsed
+	}
+	return opt, nil
+}
+
+func parseMultiCityInput(r *http.Request) (usecase.MultiCityInput, error) {
+	var req MultiCityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return usecase.MultiCityInput{}, pkgerror.NewInvalidFormat()
+	}
+
+	if len(req.Legs) < minMultiCityLegs || len(req.Legs) > maxMultiCityLegs {
+		msg := fmt.Sprintf("legs must contain between %d and %d entries", minMultiCityLegs, maxMultiCityLegs)
+		return usecase.MultiCityInput{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+	}
+
+	adults := req.Adults
+	if adults == 0 {
+		adults = req.Passengers
+	}
+	if adults == 0 {
+		adults = 1
+	}
+	passengers, err := newPassengers(adults, req.Children, req.Infants)
+	if err != nil {
+		return usecase.MultiCityInput{}, err
+	}
+
+	cabinClass := strings.TrimSpace(req.CabinClass)
+	if cabinClass == "" {
+		cabinClass = "economy"
+	}
+
+	currencyCode, err := parseCurrency(req.Currency)
+	if err != nil {
+		return usecase.MultiCityInput{}, err
+	}
+
+	sortOpt, err := parseSort(req.Sort, req.Order)
+	if err != nil {
+		return usecase.MultiCityInput{}, err
+	}
+
+	legs := make([]usecase.MultiCityLeg, 0, len(req.Legs))
+	for i, legReq := range req.Legs {
+		leg, err := parseMultiCityLeg(i, legReq)
+		if err != nil {
+			return usecase.MultiCityInput{}, err
+		}
+		if i > 0 && leg.DepartureDate.Before(legs[i-1].DepartureDate) {
+			msg := fmt.Sprintf("legs[%d]: departure_date must not be before the previous leg", i)
+			return usecase.MultiCityInput{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+		}
+		legs = append(legs, leg)
+	}
+
+	return usecase.MultiCityInput{
+		Legs:       legs,
+		Passengers: passengers,
+		CabinClass: strings.ToLower(cabinClass),
+		Sort:       sortOpt,
+		Currency:   currencyCode,
+	}, nil
+}
+
+func parseMultiCityLeg(idx int, req MultiCityLegRequest) (usecase.MultiCityLeg, error) {
+	origin, err := parseAirportCodes(req.Origin, fmt.Sprintf("legs[%d]: invalid origin", idx))
+	if err != nil {
+		return usecase.MultiCityLeg{}, err
+	}
+	destination, err := parseAirportCodes(req.Destination, fmt.Sprintf("legs[%d]: invalid destination", idx))
+	if err != nil {
+		return usecase.MultiCityLeg{}, err
+	}
+	if origin == "" || destination == "" {
+		msg := fmt.Sprintf("legs[%d]: origin and destination are required", idx)
+		return usecase.MultiCityLeg{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+	}
+
+	departureDate, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.DepartureDate), time.Local)
+	if err != nil {
+		msg := fmt.Sprintf("legs[%d]: invalid departure_date", idx)
+		return usecase.MultiCityLeg{}, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+	}
+
+	values, err := valuesFromMap(req.Filters)
+	if err != nil {
+		return usecase.MultiCityLeg{}, err
+	}
+	filters, err := parseFlightFilters(values)
+	if err != nil {
+		return usecase.MultiCityLeg{}, err
+	}
+
+	return usecase.MultiCityLeg{
+		Origin:        origin,
+		Destination:   destination,
+		DepartureDate: departureDate,
+		Filters:       filters,
+	}, nil
+}
+
+func parseFlightFilters(q url.Values) (usecase.FlightFilters, error) {
 	filters := usecase.FlightFilters{}
-	if err := parseIntFilter(q, "min_price", "minPrice", "invalid min_price", &filters.MinPrice); err != nil {
+	if err := parseFloatFilter(q, "min_price", "minPrice", "invalid min_price", &filters.MinPrice); err != nil {
 		return filters, err
 	}
-	if err := parseIntFilter(q, "max_price", "maxPrice", "invalid max_price", &filters.MaxPrice); err != nil {
+	if err := parseFloatFilter(q, "max_price", "maxPrice", "invalid max_price", &filters.MaxPrice); err != nil {
 		return filters, err
 	}
+	switch basis := strings.ToLower(strings.TrimSpace(firstNotEmpty(q.Get("price_basis"), q.Get("priceBasis")))); basis {
+	case "", usecase.PriceBasisPerPassenger:
+		filters.PriceBasis = usecase.PriceBasisPerPassenger
+	case usecase.PriceBasisTotal:
+		filters.PriceBasis = usecase.PriceBasisTotal
+	default:
+		return filters, pkgerror.NewBusiness("invalid price_basis", pkgerror.CodeInvalidInput)
+	}
 	if err := parseIntFilter(q, "stops", "stop_count", "invalid stops", &filters.Stops); err != nil {
 		return filters, err
 	}
@@ -97,24 +506,44 @@ func parseFlightFilters(q url.Values, departureDate time.Time) (usecase.FlightFi
 	if err := parseIntFilter(q, "max_duration", "maxDuration", "invalid max_duration", &filters.MaxDuration); err != nil {
 		return filters, err
 	}
+	if err := parseIntFilter(q, "min_layover", "minLayover", "invalid min_layover", &filters.MinLayover); err != nil {
+		return filters, err
+	}
+	if err := parseIntFilter(q, "max_layover", "maxLayover", "invalid max_layover", &filters.MaxLayover); err != nil {
+		return filters, err
+	}
+	filters.Via = parseListFilter(q, "via", "connecting_airports")
 	filters.Airlines = parseListFilter(q, "airlines", "airline")
+	airlineTypes, err := parseEnumListFilter(q, "airline_type", "airlineType", "invalid airline_type", airline.IsType)
+	if err != nil {
+		return filters, err
+	}
+	filters.AirlineTypes = airlineTypes
+	alliances, err := parseEnumListFilter(q, "alliance", "alliances", "invalid alliance", airline.IsAlliance)
+	if err != nil {
+		return filters, err
+	}
+	filters.Alliances = alliances
+	if err := parseServiceFilters(q, &filters); err != nil {
+		return filters, err
+	}
 
-	departAfter, err := parseTimeFilter(q, "depart_after", "departAfter", departureDate)
+	departAfter, err := parseTimeFilter(q, "depart_after", "departAfter")
 	if err != nil {
 		return filters, err
 	}
 	filters.DepartAfter = departAfter
-	departBefore, err := parseTimeFilter(q, "depart_before", "departBefore", departureDate)
+	departBefore, err := parseTimeFilter(q, "depart_before", "departBefore")
 	if err != nil {
 		return filters, err
 	}
 	filters.DepartBefore = departBefore
-	arriveAfter, err := parseTimeFilter(q, "arrive_after", "arriveAfter", departureDate)
+	arriveAfter, err := parseTimeFilter(q, "arrive_after", "arriveAfter")
 	if err != nil {
 		return filters, err
 	}
 	filters.ArriveAfter = arriveAfter
-	arriveBefore, err := parseTimeFilter(q, "arrive_before", "arriveBefore", departureDate)
+	arriveBefore, err := parseTimeFilter(q, "arrive_before", "arriveBefore")
 	if err != nil {
 		return filters, err
 	}
@@ -123,6 +552,36 @@ func parseFlightFilters(q url.Values, departureDate time.Time) (usecase.FlightFi
 	return filters, nil
 }
 
+// parseServiceFilters reads the amenity and baggage filters.
+func parseServiceFilters(q url.Values, filters *usecase.FlightFilters) error {
+	amenities, err := parseEnumListFilter(q, "amenities", "amenity", "invalid amenities", usecase.IsAmenity)
+	if err != nil {
+		return err
+	}
+	filters.Amenities = amenities
+
+	// Booleans are accepted too: checked_baggage=true reads as included.
+	switch value := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(firstNotEmpty(q.Get("checked_baggage"), q.Get("checkedBaggage")))), "-", "_"); {
+	case value == "":
+	case value == "true":
+		filters.CheckedBaggage = usecase.CheckedBaggageIncluded
+	case value == "false":
+		filters.CheckedBaggage = usecase.CheckedBaggageNotIncluded
+	case usecase.IsCheckedBaggage(value):
+		filters.CheckedBaggage = value
+	default:
+		return pkgerror.NewBusiness("invalid checked_baggage, use included, not_included, true or false", pkgerror.CodeInvalidInput)
+	}
+
+	if err := parseIntFilter(q, "min_checked_kg", "minCheckedKg", "invalid min_checked_kg", &filters.MinCheckedKg); err != nil {
+		return err
+	}
+	if filters.MinCheckedKg != nil && *filters.MinCheckedKg < 0 {
+		return pkgerror.NewBusiness("invalid min_checked_kg", pkgerror.CodeInvalidInput)
+	}
+	return nil
+}
+
 func parseIntFilter(q url.Values, key, altKey, errMsg string, target **int) error {
 	value := strings.TrimSpace(firstNotEmpty(q.Get(key), q.Get(altKey)))
 	if value == "" {
@@ -136,6 +595,35 @@ func parseIntFilter(q url.Values, key, altKey, errMsg string, target **int) erro
 	return nil
 }
 
+func parseFloatFilter(q url.Values, key, altKey, errMsg string, target **float64) error {
+	value := strings.TrimSpace(firstNotEmpty(q.Get(key), q.Get(altKey)))
+	if value == "" {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return pkgerror.NewBusiness(errMsg, pkgerror.CodeInvalidInput)
+	}
+	*target = &parsed
+	return nil
+}
+
+func parseCurrency(value string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(value))
+	if code == "" {
+		return "", nil
+	}
+	if len(code) != 3 {
+		return "", pkgerror.NewBusiness("invalid currency", pkgerror.CodeInvalidInput)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", pkgerror.NewBusiness("invalid currency", pkgerror.CodeInvalidInput)
+		}
+	}
+	return code, nil
+}
+
 func parseListFilter(q url.Values, key, altKey string) []string {
 	value := strings.TrimSpace(firstNotEmpty(q.Get(key), q.Get(altKey)))
 	if value == "" {
@@ -144,24 +632,79 @@ func parseListFilter(q url.Values, key, altKey string) []string {
 	return strings.Split(value, ",")
 }
 
-func parseTimeFilter(q url.Values, key, altKey string, date time.Time) (*time.Time, error) {
+// parseEnumListFilter reads a comma-separated list of fixed values such as
+// full_service,lcc; dashes are accepted in place of underscores.
+func parseEnumListFilter(q url.Values, key, altKey, errMsg string, valid func(string) bool) ([]string, error) {
+	values := parseListFilter(q, key, altKey)
+	for i, value := range values {
+		value = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "-", "_")
+		if !valid(value) {
+			return nil, pkgerror.NewBusiness(errMsg, pkgerror.CodeInvalidInput)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// parseTimeFilter accepts an RFC3339 instant or an HH:MM wall-clock time,
+// which the search reads in the local time of the airport concerned.
+func parseTimeFilter(q url.Values, key, altKey string) (*usecase.TimeBound, error) {
 	value := strings.TrimSpace(firstNotEmpty(q.Get(key), q.Get(altKey)))
 	if value == "" {
 		return nil, nil
 	}
 	if len(value) == 5 && strings.Contains(value, ":") {
-		parsed, err := time.ParseInLocation("15:04", value, time.Local)
+		parsed, err := time.Parse("15:04", value)
 		if err != nil {
 			return nil, pkgerror.NewBusiness("invalid time filter", pkgerror.CodeInvalidInput)
 		}
-		combined := time.Date(date.Year(), date.Month(), date.Day(), parsed.Hour(), parsed.Minute(), 0, 0, time.Local)
-		return &combined, nil
+		return &usecase.TimeBound{Local: true, Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
 	}
 	parsed, err := time.Parse(time.RFC3339, value)
 	if err != nil {
 		return nil, pkgerror.NewBusiness("invalid time filter", pkgerror.CodeInvalidInput)
 	}
-	return &parsed, nil
+	return &usecase.TimeBound{Instant: parsed}, nil
+}
+
+func valuesFromMap(m map[string]any) (url.Values, error) {
+	values := url.Values{}
+	for key, raw := range m {
+		value, err := stringifyValue(raw)
+		if err != nil {
+			return nil, pkgerror.NewBusiness("invalid "+key, pkgerror.CodeInvalidInput)
+		}
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values, nil
+}
+
+//nolint:err113 // the caller maps it to a business error
+func stringifyValue(raw any) (string, error) {
+	switch v := raw.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			part, err := stringifyValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", errors.New("unsupported value type")
+	}
 }
 
 func firstNotEmpty(values ...string) string {
@@ -182,6 +725,74 @@ func mapFlightPoint(point entity.FlightPoint) FlightPoint {
 	}
 }
 
+func mapSegmentPoint(point entity.FlightPoint) SegmentPoint {
+	resp := SegmentPoint{Airport: point.Airport, City: point.City}
+	if !point.Time.IsZero() {
+		resp.Datetime = point.Time.Format(time.RFC3339)
+		resp.Timestamp = point.Time.Unix()
+	}
+	return resp
+}
+
+func mapPrice(price entity.Price) PriceResponse {
+	code := price.Currency
+	resp := PriceResponse{
+		Amount:         currency.ToMajor(price.Amount, code),
+		TotalAmount:    currency.ToMajor(price.Total, code),
+		Passengers:     price.Passengers,
+		Currency:       code,
+		Formatted:      formatMoney(price.Amount, code),
+		FormattedTotal: formatMoney(price.Total, code),
+		Fares:          make([]PassengerFareResponse, 0, len(price.Fares)),
+	}
+	for _, fare := range price.Fares {
+		resp.Fares = append(resp.Fares, PassengerFareResponse{
+			Type:           fare.Type,
+			Count:          fare.Count,
+			Amount:         currency.ToMajor(fare.Amount, code),
+			Total:          currency.ToMajor(fare.Total, code),
+			Formatted:      formatMoney(fare.Amount, code),
+			FormattedTotal: formatMoney(fare.Total, code),
+		})
+	}
+	if price.Breakdown != nil {
+		resp.Breakdown = &PriceBreakdownResponse{
+			BaseFare:          currency.ToMajor(price.Breakdown.BaseFare, code),
+			Taxes:             currency.ToMajor(price.Breakdown.Taxes, code),
+			Fees:              currency.ToMajor(price.Breakdown.Fees, code),
+			FormattedBaseFare: formatMoney(price.Breakdown.BaseFare, code),
+			FormattedTaxes:    formatMoney(price.Breakdown.Taxes, code),
+			FormattedFees:     formatMoney(price.Breakdown.Fees, code),
+		}
+	}
+	return resp
+}
+
+func mapPriceRange(rng *usecase.PriceRange) *PriceRangeResponse {
+	if rng == nil {
+		return nil
+	}
+	return &PriceRangeResponse{
+		Min:               currency.ToMajor(rng.Min, rng.Currency),
+		Max:               currency.ToMajor(rng.Max, rng.Currency),
+		MinTotal:          currency.ToMajor(rng.MinTotal, rng.Currency),
+		MaxTotal:          currency.ToMajor(rng.MaxTotal, rng.Currency),
+		Currency:          rng.Currency,
+		FormattedMin:      formatMoney(rng.Min, rng.Currency),
+		FormattedMax:      formatMoney(rng.Max, rng.Currency),
+		FormattedMinTotal: formatMoney(rng.MinTotal, rng.Currency),
+		FormattedMaxTotal: formatMoney(rng.MaxTotal, rng.Currency),
+	}
+}
+
+func formatOptionalTime(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := value.Format(time.RFC3339)
+	return &formatted
+}
+
 func formatDuration(minutes int) string {
 	if minutes <= 0 {
 		return ""
@@ -197,20 +808,52 @@ func formatDuration(minutes int) string {
 	return fmt.Sprintf("%dh %dm", hours, mins)
 }
 
-func formatIDR(amount int) string {
-	if amount == 0 {
-		return "Rp. 0"
+type moneyFormat struct {
+	symbol   string
+	thousand string
+	decimal  string
+}
+
+func moneyFormatFor(code string) moneyFormat {
+	switch code {
+	case "IDR":
+		return moneyFormat{symbol: "Rp. ", thousand: ".", decimal: ","}
+	case "USD":
+		return moneyFormat{symbol: "$", thousand: ",", decimal: "."}
+	case "SGD":
+		return moneyFormat{symbol: "S$", thousand: ",", decimal: "."}
+	case "AUD":
+		return moneyFormat{symbol: "A$", thousand: ",", decimal: "."}
+	case "MYR":
+		return moneyFormat{symbol: "RM ", thousand: ",", decimal: "."}
+	case "EUR":
+		return moneyFormat{symbol: "€", thousand: ".", decimal: ","}
+	case "JPY":
+		return moneyFormat{symbol: "¥", thousand: ",", decimal: "."}
+	default:
+		return moneyFormat{symbol: code + " ", thousand: ",", decimal: "."}
 	}
+}
+
+func formatMoney(amount int, code string) string {
+	format := moneyFormatFor(code)
 	negative := amount < 0
 	if negative {
 		amount = -amount
 	}
-	value := strconv.Itoa(amount)
+
+	exponent := currency.Exponent(code)
+	unit := int(math.Pow10(exponent))
+	value := strconv.Itoa(amount / unit)
 	for i := len(value) - 3; i > 0; i -= 3 {
-		value = value[:i] + "." + value[i:]
+		value = value[:i] + format.thousand + value[i:]
 	}
+	if exponent > 0 {
+		value += format.decimal + fmt.Sprintf("%0*d", exponent, amount%unit)
+	}
+
 	if negative {
-		return "-Rp. " + value
+		return "-" + format.symbol + value
 	}
-	return "Rp. " + value
+	return format.symbol + value
 }
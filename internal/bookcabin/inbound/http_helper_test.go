@@ -0,0 +1,195 @@
+package inbound
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
+)
+
+func TestParsePassengers(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    entity.Passengers
+		wantErr string
+	}{
+		{name: "defaults to one adult", query: "", want: entity.Passengers{Adults: 1}},
+		{name: "legacy passengers count", query: "passengers=3", want: entity.Passengers{Adults: 3}},
+		{name: "full mix", query: "adults=2&children=1&infants=2", want: entity.Passengers{Adults: 2, Children: 1, Infants: 2}},
+		{name: "alternate keys", query: "adults=1&childs=2&infant=1", want: entity.Passengers{Adults: 1, Children: 2, Infants: 1}},
+		{name: "infants do not take seats", query: "adults=5&children=4&infants=5", want: entity.Passengers{Adults: 5, Children: 4, Infants: 5}},
+		{name: "no adults", query: "adults=0&children=2", wantErr: "at least one adult is required"},
+		{name: "not a number", query: "adults=two", wantErr: "invalid adults"},
+		{name: "negative children", query: "children=-1", wantErr: "invalid children"},
+		{name: "negative infants", query: "infants=-1", wantErr: "invalid infants"},
+		{name: "more infants than adults", query: "adults=1&infants=2", wantErr: "infants must not exceed adults"},
+		{name: "too many seats", query: "adults=6&children=4", wantErr: "adults and children must not exceed 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			got, err := parsePassengers(q)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePassengers: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount int
+		code   string
+		want   string
+	}{
+		{amount: 1250000, code: "IDR", want: "Rp. 1.250.000"},
+		{amount: 500, code: "IDR", want: "Rp. 500"},
+		{amount: 0, code: "IDR", want: "Rp. 0"},
+		{amount: 7813, code: "USD", want: "$78.13"},
+		{amount: 123456705, code: "USD", want: "$1,234,567.05"},
+		{amount: 5, code: "SGD", want: "S$0.05"},
+		{amount: 123450, code: "EUR", want: "€1.234,50"},
+		{amount: 11875, code: "JPY", want: "¥11,875"},
+		{amount: -7813, code: "USD", want: "-$78.13"},
+		{amount: 100000, code: "GBP", want: "GBP 1,000.00"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.code); got != tt.want {
+			t.Fatalf("formatMoney(%d, %s): expected %q, got %q", tt.amount, tt.code, tt.want, got)
+		}
+	}
+}
+
+func TestParseScoreOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    usecase.ScoreOptions
+		wantErr string
+	}{
+		{name: "nothing set", query: ""},
+		{name: "only the listed factors", query: "weights=price:0.5,+Stops:0.3", want: usecase.ScoreOptions{Weights: usecase.ScoreWeights{"price": 0.5, "stops": 0.3}}},
+		{name: "zero turns a factor off", query: "weights=price:0", want: usecase.ScoreOptions{Weights: usecase.ScoreWeights{"price": 0}}},
+		{name: "preferred time", query: "prefer_time=Morning", want: usecase.ScoreOptions{PreferredTime: "morning"}},
+		{name: "unknown factor", query: "weights=comfort:1", wantErr: "invalid weights"},
+		{name: "missing weight", query: "weights=price", wantErr: "invalid weights"},
+		{name: "negative weight", query: "weights=price:-1", wantErr: "invalid weights"},
+		{name: "unknown time of day", query: "prefer_time=noon", wantErr: "invalid prefer_time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			got, err := parseScoreOptions(q)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseScoreOptions: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    string
+		order   string
+		want    []usecase.SortOption
+		wantErr string
+	}{
+		{name: "default", want: []usecase.SortOption{{Field: "best_value", Order: "asc"}}},
+		{name: "order applies to keys without one", sort: "price,departure:asc", order: "desc", want: []usecase.SortOption{{Field: "price", Order: "desc"}, {Field: "departure", Order: "asc"}}},
+		{name: "case and spaces", sort: " Arrival_Local : DESC ", want: []usecase.SortOption{{Field: "arrival_local", Order: "desc"}}},
+		{name: "unknown field", sort: "price,comfort", wantErr: `invalid sort field "comfort"`},
+		{name: "unknown key order", sort: "price:up", wantErr: "invalid sort order for price"},
+		{name: "unknown order", sort: "price", order: "up", wantErr: "invalid order"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSort(tt.sort, tt.order)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSort: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseServiceFilters(t *testing.T) {
+	checkedErr := "invalid checked_baggage, use included, not_included, true or false"
+	tests := []struct {
+		name        string
+		query       string
+		wantChecked string
+		wantMinKg   int
+		wantErr     string
+	}{
+		{name: "nothing set", query: ""},
+		{name: "included", query: "checked_baggage=included", wantChecked: "included"},
+		{name: "not included with a dash", query: "checked_baggage=Not-Included", wantChecked: "not_included"},
+		{name: "true", query: "checked_baggage=true", wantChecked: "included"},
+		{name: "false", query: "checkedBaggage=FALSE", wantChecked: "not_included"},
+		{name: "unknown value", query: "checked_baggage=maybe", wantErr: checkedErr},
+		{name: "minimum weight", query: "min_checked_kg=20", wantMinKg: 20},
+		{name: "negative weight", query: "min_checked_kg=-1", wantErr: "invalid min_checked_kg"},
+		{name: "unknown amenity", query: "amenities=wifi,spa", wantErr: "invalid amenities"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			var filters usecase.FlightFilters
+			err = parseServiceFilters(q, &filters)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseServiceFilters: %v", err)
+			}
+			if filters.CheckedBaggage != tt.wantChecked {
+				t.Fatalf("expected checked baggage %q, got %q", tt.wantChecked, filters.CheckedBaggage)
+			}
+			if tt.wantMinKg > 0 && (filters.MinCheckedKg == nil || *filters.MinCheckedKg != tt.wantMinKg) {
+				t.Fatalf("expected min checked kg %d, got %v", tt.wantMinKg, filters.MinCheckedKg)
+			}
+		})
+	}
+}
@@ -1,50 +1,331 @@
 package inbound
 
+import "net/http"
+
 type FlightsResponse struct {
 	SearchCriteria SearchCriteriaResponse `json:"search_criteria"`
 	Metadata       MetadataResponse       `json:"metadata"`
 	Flights        []FlightResponse       `json:"flights"`
 	ReturnFlights  []FlightResponse       `json:"return_flights,omitempty"`
+	Itineraries    []ItineraryResponse    `json:"itineraries,omitempty"`
+	Facets         FacetsResponse         `json:"facets"`
+	ReturnFacets   *FacetsResponse        `json:"return_facets,omitempty"`
+}
+
+type FacetsResponse struct {
+	Total          int                    `json:"total"`
+	Airlines       []FacetBucketResponse  `json:"airlines"`
+	Stops          []FacetBucketResponse  `json:"stops"`
+	DepartureTimes []FacetBucketResponse  `json:"departure_times"`
+	Price          *PriceRangeResponse    `json:"price"`
+	PriceHistogram []PriceBucketResponse  `json:"price_histogram"`
+	Duration       *DurationRangeResponse `json:"duration"`
+}
+
+type FacetBucketResponse struct {
+	Value             string  `json:"value"`
+	Label             string  `json:"label,omitempty"`
+	Count             int     `json:"count"`
+	MinPrice          float64 `json:"min_price"`
+	FormattedMinPrice string  `json:"formatted_min_price"`
+}
+
+type PriceBucketResponse struct {
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	Count        int     `json:"count"`
+	FormattedMin string  `json:"formatted_min"`
+	FormattedMax string  `json:"formatted_max"`
+}
+
+type DurationRangeResponse struct {
+	Min DurationResponse `json:"min"`
+	Max DurationResponse `json:"max"`
+}
+
+type FlightsStreamEventResponse struct {
+	Leg         string                  `json:"leg"`
+	Provider    *ProviderStatusResponse `json:"provider,omitempty"`
+	Flights     []FlightResponse        `json:"flights"`
+	ReplacedIDs []string                `json:"replaced_ids"`
+}
+
+type FlightsStreamSummaryResponse struct {
+	SearchCriteria SearchCriteriaResponse `json:"search_criteria"`
+	Metadata       MetadataResponse       `json:"metadata"`
+}
+
+type FareCalendarResponse struct {
+	SearchCriteria SearchCriteriaResponse       `json:"search_criteria"`
+	Metadata       FareCalendarMetadataResponse `json:"metadata"`
+	Days           []FareCalendarDayResponse    `json:"days"`
+}
+
+type FareCalendarMetadataResponse struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	TotalDays       int     `json:"total_days"`
+	DaysWithResults int     `json:"days_with_results"`
+	CheapestDate    *string `json:"cheapest_date"`
+	CacheHits       int     `json:"cache_hits"`
+	Partial         bool    `json:"partial"`
+	SearchTimeMs    int64   `json:"search_time_ms"`
+}
+
+type FareCalendarDayResponse struct {
+	Date            string               `json:"date"`
+	Results         int                  `json:"results"`
+	LowestPrice     *LowestPriceResponse `json:"lowest_price"`
+	CheapestAirline *AirlineResponse     `json:"cheapest_airline"`
+	CheapestFlight  *string              `json:"cheapest_flight_id"`
+	CacheHit        bool                 `json:"cache_hit"`
+	Partial         bool                 `json:"partial"`
+}
+
+type LowestPriceResponse struct {
+	Amount         float64 `json:"amount"`
+	Total          float64 `json:"total"`
+	Currency       string  `json:"currency"`
+	Formatted      string  `json:"formatted"`
+	FormattedTotal string  `json:"formatted_total"`
+}
+
+type SearchJobResponse struct {
+	ID             string                   `json:"id"`
+	Status         string                   `json:"status"`
+	Error          string                   `json:"error,omitempty"`
+	CreatedAt      string                   `json:"created_at"`
+	StartedAt      *string                  `json:"started_at"`
+	FinishedAt     *string                  `json:"finished_at"`
+	ExpiresAt      *string                  `json:"expires_at"`
+	SearchCriteria *SearchCriteriaResponse  `json:"search_criteria,omitempty"`
+	Metadata       *MetadataResponse        `json:"metadata,omitempty"`
+	Providers      []ProviderStatusResponse `json:"providers"`
+	Flights        []FlightResponse         `json:"flights"`
+	ReturnFlights  []FlightResponse         `json:"return_flights,omitempty"`
+	Itineraries    []ItineraryResponse      `json:"itineraries,omitempty"`
+}
+
+type SearchJobAcceptedResponse struct {
+	SearchJobResponse
+}
+
+func (SearchJobAcceptedResponse) StatusCode() int {
+	return http.StatusAccepted
+}
+
+type ItineraryResponse struct {
+	ID             string                `json:"id"`
+	Outbound       FlightResponse        `json:"outbound"`
+	Return         FlightResponse        `json:"return"`
+	TotalPrice     PriceResponse         `json:"total_price"`
+	TotalDuration  DurationResponse      `json:"total_duration"`
+	Turnaround     DurationResponse      `json:"turnaround"`
+	BestValueScore float64               `json:"best_value_score"`
+	ScoreBreakdown []ScoreFactorResponse `json:"score_breakdown"`
+}
+
+// ScoreFactorResponse explains one part of a best value score: score runs
+// from 0 (best in the results) to 1 (worst), and contribution is its share of
+// best_value_score.
+type ScoreFactorResponse struct {
+	Factor       string  `json:"factor"`
+	Weight       float64 `json:"weight"`
+	Score        float64 `json:"score"`
+	Contribution float64 `json:"contribution"`
+}
+
+type MultiCityRequest struct {
+	Legs       []MultiCityLegRequest `json:"legs"`
+	Passengers int                   `json:"passengers"`
+	Adults     int                   `json:"adults"`
+	Children   int                   `json:"children"`
+	Infants    int                   `json:"infants"`
+	CabinClass string                `json:"cabin_class"`
+	Sort       string                `json:"sort"`
+	Order      string                `json:"order"`
+	Currency   string                `json:"currency"`
+}
+
+type MultiCityLegRequest struct {
+	Origin        string         `json:"origin"`
+	Destination   string         `json:"destination"`
+	DepartureDate string         `json:"departure_date"`
+	Filters       map[string]any `json:"filters"`
+}
+
+type MultiCityResponse struct {
+	Metadata MultiCityMetadataResponse `json:"metadata"`
+	Legs     []MultiCityLegResponse    `json:"legs"`
+}
+
+type MultiCityMetadataResponse struct {
+	TotalLegs          int                 `json:"total_legs"`
+	TotalResults       int                 `json:"total_results"`
+	Complete           bool                `json:"complete"`
+	EmptyLegs          []int               `json:"empty_legs"`
+	TotalPriceRange    *PriceRangeResponse `json:"total_price_range"`
+	ProvidersSucceeded []int               `json:"providers_succeeded"`
+	SearchTimeMs       int64               `json:"search_time_ms"`
+}
+
+type MultiCityLegResponse struct {
+	SearchCriteria SearchCriteriaResponse `json:"search_criteria"`
+	Metadata       MetadataResponse       `json:"metadata"`
+	Flights        []FlightResponse       `json:"flights"`
+}
+
+type PriceRangeResponse struct {
+	Min               float64 `json:"min"`
+	Max               float64 `json:"max"`
+	MinTotal          float64 `json:"min_total"`
+	MaxTotal          float64 `json:"max_total"`
+	Currency          string  `json:"currency"`
+	FormattedMin      string  `json:"formatted_min"`
+	FormattedMax      string  `json:"formatted_max"`
+	FormattedMinTotal string  `json:"formatted_min_total"`
+	FormattedMaxTotal string  `json:"formatted_max_total"`
 }
 
 type SearchCriteriaResponse struct {
-	Origin        string  `json:"origin"`
-	Destination   string  `json:"destination"`
-	DepartureDate string  `json:"departure_date"`
-	ReturnDate    *string `json:"return_date,omitempty"`
-	Passengers    int     `json:"passengers"`
-	CabinClass    string  `json:"cabin_class"`
+	Origin              string               `json:"origin"`
+	Destination         string               `json:"destination"`
+	OriginAirports      []string             `json:"origin_airports,omitempty"`
+	DestinationAirports []string             `json:"destination_airports,omitempty"`
+	DepartureDate       string               `json:"departure_date"`
+	ReturnDate          *string              `json:"return_date,omitempty"`
+	Passengers          int                  `json:"passengers"`
+	PassengerMix        PassengerMixResponse `json:"passenger_mix"`
+	CabinClass          string               `json:"cabin_class"`
+	Currency            string               `json:"currency"`
+}
+
+type PassengerMixResponse struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	Infants  int `json:"infants"`
 }
 
 type MetadataResponse struct {
-	TotalResults       int   `json:"total_results"`
-	ProvidersQueried   int   `json:"providers_queried"`
-	ProvidersSucceeded int   `json:"providers_succeeded"`
-	ProvidersFailed    int   `json:"providers_failed"`
-	SearchTimeMs       int64 `json:"search_time_ms"`
-	CacheHit           bool  `json:"cache_hit"`
+	TotalResults       int                      `json:"total_results"`
+	TotalBeforeFilters int                      `json:"total_before_filters"`
+	ProvidersQueried   int                      `json:"providers_queried"`
+	ProvidersSucceeded int                      `json:"providers_succeeded"`
+	ProvidersFailed    int                      `json:"providers_failed"`
+	ProvidersPending   int                      `json:"providers_pending"`
+	FailedProviders    []FailedProviderResponse `json:"failed_providers"`
+	PendingProviders   []string                 `json:"pending_providers"`
+	Providers          []ProviderStatusResponse `json:"providers"`
+	SearchTimeMs       int64                    `json:"search_time_ms"`
+	CacheHit           bool                     `json:"cache_hit"`
+	Partial            bool                     `json:"partial"`
+	Offset             int                      `json:"offset,omitempty"`
+	Limit              int                      `json:"limit,omitempty"`
+	NextCursor         *string                  `json:"next_cursor,omitempty"`
+	NextReturnCursor   *string                  `json:"next_return_cursor,omitempty"`
+}
+
+type FailedProviderResponse struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type ProviderStatusResponse struct {
+	Name       string `json:"name"`
+	Leg        string `json:"leg"`
+	Route      string `json:"route"`
+	Outcome    string `json:"outcome"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Attempts   int    `json:"attempts"`
+	Hedged     bool   `json:"hedged"`
+	LatencyMs  int64  `json:"latency_ms"`
+	Results    int    `json:"results"`
+}
+
+type CircuitBreakersResponse struct {
+	Breakers []CircuitBreakerResponse `json:"breakers"`
+}
+
+type CircuitBreakerResponse struct {
+	Provider            string  `json:"provider"`
+	State               string  `json:"state"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	FailureThreshold    int     `json:"failure_threshold"`
+	CoolDownMs          int64   `json:"cool_down_ms"`
+	OpenedAt            *string `json:"opened_at"`
+	RetryAt             *string `json:"retry_at"`
 }
 
 type FlightResponse struct {
-	ID             string           `json:"id"`
-	Provider       string           `json:"provider"`
-	Airline        AirlineResponse  `json:"airline"`
-	FlightNumber   string           `json:"flight_number"`
-	Departure      FlightPoint      `json:"departure"`
-	Arrival        FlightPoint      `json:"arrival"`
-	Duration       DurationResponse `json:"duration"`
-	Stops          int              `json:"stops"`
-	Price          PriceResponse    `json:"price"`
-	AvailableSeats int              `json:"available_seats"`
-	CabinClass     string           `json:"cabin_class"`
-	Aircraft       *string          `json:"aircraft"`
-	Amenities      []string         `json:"amenities"`
-	Baggage        BaggageResponse  `json:"baggage"`
+	ID                    string                `json:"id"`
+	Provider              string                `json:"provider"`
+	Airline               AirlineResponse       `json:"airline"`
+	FlightNumber          string                `json:"flight_number"`
+	OperatingAirline      *AirlineResponse      `json:"operating_airline,omitempty"`
+	OperatingFlightNumber string                `json:"operating_flight_number,omitempty"`
+	Departure             FlightPoint           `json:"departure"`
+	Arrival               FlightPoint           `json:"arrival"`
+	Duration              DurationResponse      `json:"duration"`
+	Stops                 int                   `json:"stops"`
+	Segments              []SegmentResponse     `json:"segments"`
+	Price                 PriceResponse         `json:"price"`
+	AvailableSeats        int                   `json:"available_seats"`
+	CabinClass            string                `json:"cabin_class"`
+	FareClass             string                `json:"fare_class,omitempty"`
+	Aircraft              *string               `json:"aircraft"`
+	Amenities             []string              `json:"amenities"`
+	Baggage               BaggageResponse       `json:"baggage"`
+	BestValueScore        float64               `json:"best_value_score"`
+	ScoreBreakdown        []ScoreFactorResponse `json:"score_breakdown"`
+	Offers                []OfferResponse       `json:"offers"`
+}
+
+type OfferResponse struct {
+	FlightID       string          `json:"flight_id"`
+	Provider       string          `json:"provider"`
+	Airline        AirlineResponse `json:"airline"`
+	FlightNumber   string          `json:"flight_number"`
+	Price          PriceResponse   `json:"price"`
+	AvailableSeats int             `json:"available_seats"`
+	Baggage        BaggageResponse `json:"baggage"`
+	CabinClass     string          `json:"cabin_class"`
+	FareClass      string          `json:"fare_class,omitempty"`
+}
+
+type SegmentResponse struct {
+	FlightNumber string            `json:"flight_number"`
+	Departure    SegmentPoint      `json:"departure"`
+	Arrival      SegmentPoint      `json:"arrival"`
+	Duration     *DurationResponse `json:"duration,omitempty"`
+	Layover      *DurationResponse `json:"layover,omitempty"`
+}
+
+type SegmentPoint struct {
+	Airport   string `json:"airport"`
+	City      string `json:"city"`
+	Datetime  string `json:"datetime,omitempty"`
+	Timestamp int64  `json:"timestamp,omitempty"`
 }
 
 type AirlineResponse struct {
-	Name string `json:"name"`
-	Code string `json:"code"`
+	Name     string `json:"name"`
+	Code     string `json:"code"`
+	ICAO     string `json:"icao,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Alliance string `json:"alliance,omitempty"`
+}
+
+type AirlinesResponse struct {
+	Airlines []AirlineDetailResponse `json:"airlines"`
+}
+
+type AirlineDetailResponse struct {
+	Code     string   `json:"code"`
+	ICAO     string   `json:"icao"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Type     string   `json:"type"`
+	Alliance *string  `json:"alliance"`
 }
 
 type FlightPoint struct {
@@ -60,12 +341,45 @@ type DurationResponse struct {
 }
 
 type PriceResponse struct {
-	Amount    int    `json:"amount"`
-	Currency  string `json:"currency"`
-	Formatted string `json:"formatted"`
+	Amount         float64                 `json:"amount"`
+	TotalAmount    float64                 `json:"total_amount"`
+	Passengers     int                     `json:"passengers"`
+	Currency       string                  `json:"currency"`
+	Formatted      string                  `json:"formatted"`
+	FormattedTotal string                  `json:"formatted_total"`
+	Breakdown      *PriceBreakdownResponse `json:"breakdown,omitempty"`
+	Fares          []PassengerFareResponse `json:"fares"`
+}
+
+type PassengerFareResponse struct {
+	Type           string  `json:"type"`
+	Count          int     `json:"count"`
+	Amount         float64 `json:"amount"`
+	Total          float64 `json:"total"`
+	Formatted      string  `json:"formatted"`
+	FormattedTotal string  `json:"formatted_total"`
 }
 
+type PriceBreakdownResponse struct {
+	BaseFare          float64 `json:"base_fare"`
+	Taxes             float64 `json:"taxes"`
+	Fees              float64 `json:"fees"`
+	FormattedBaseFare string  `json:"formatted_base_fare"`
+	FormattedTaxes    string  `json:"formatted_taxes"`
+	FormattedFees     string  `json:"formatted_fees"`
+}
+
+// BaggageResponse keeps the provider's wording in carry_on and checked next
+// to the structured allowances.
 type BaggageResponse struct {
-	CarryOn string `json:"carry_on"`
-	Checked string `json:"checked"`
+	CarryOn          string                   `json:"carry_on"`
+	Checked          string                   `json:"checked"`
+	CarryOnAllowance BaggageAllowanceResponse `json:"carry_on_allowance"`
+	CheckedAllowance BaggageAllowanceResponse `json:"checked_allowance"`
+}
+
+type BaggageAllowanceResponse struct {
+	Included bool `json:"included"`
+	Pieces   *int `json:"pieces"`
+	WeightKg *int `json:"weight_kg"`
 }
@@ -1,37 +1,80 @@
 package bookcabin
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/cache"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/inbound"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
 	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgconfig"
 	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgrouter"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkguid"
 )
 
 type Dependency struct {
 	Config pkgconfig.Config
 	Router *pkgrouter.Router
+	UUID   pkguid.StringID
 }
 
-func New(dep Dependency) error {
-	providers := []provider.Provider{
-		provider.NewGarudaIndonesiaProvider("mocks/garuda_indonesia_search_response.json"),
-		provider.NewLionAirProvider("mocks/lion_air_search_response.json"),
-		provider.NewBatikAirProvider("mocks/batik_air_search_response.json"),
-		provider.NewAirAsiaProvider("mocks/airasia_search_response.json"),
+type Module struct {
+	uc *usecase.Usecase
+}
+
+func New(dep Dependency) (*Module, error) {
+	airports, err := airport.NewRegistry()
+	if path := dep.Config.GetString("modules.book-cabin.airports.path"); path != "" {
+		airports, err = airport.NewRegistryFromFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	airlines, err := airline.NewRegistry()
+	if path := dep.Config.GetString("modules.book-cabin.airlines.path"); path != "" {
+		airlines, err = airline.NewRegistryFromFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	providerOpts := provider.Options{
+		FareRatio: provider.FareRatio{Child: 0.75, Infant: 0.1},
+		Airports:  airports,
+		Airlines:  airlines,
+	}
+	if ratio := dep.Config.GetFloat("modules.book-cabin.provider.child_fare_ratio"); ratio > 0 {
+		providerOpts.FareRatio.Child = ratio
+	}
+	if ratio := dep.Config.GetFloat("modules.book-cabin.provider.infant_fare_ratio"); ratio > 0 {
+		providerOpts.FareRatio.Infant = ratio
 	}
 
-	rateLimit := 100 * time.Millisecond
+	defaults := providerDefaults{
+		timeout:   1 * time.Second,
+		retries:   2,
+		rateLimit: 100 * time.Millisecond,
+		breaker: provider.CircuitBreakerOptions{
+			FailureThreshold: 5,
+			CoolDown:         30 * time.Second,
+		},
+	}
 	if rateLimitMs := dep.Config.GetInt("modules.book-cabin.provider.rate_limit_ms"); rateLimitMs > 0 {
-		rateLimit = time.Duration(rateLimitMs) * time.Millisecond
+		defaults.rateLimit = time.Duration(rateLimitMs) * time.Millisecond
 	}
-	if rateLimit > 0 {
-		for i := range providers {
-			providers[i] = provider.NewRateLimitedProvider(providers[i], rateLimit)
-		}
+
+	defaults.breaker = loadBreakerOptions(dep.Config, "modules.book-cabin.provider.circuit_breaker", defaults.breaker)
+
+	providers, providerOptions, err := buildProviders(dep.Config, provider.NewRegistry(), providerOpts, defaults)
+	if err != nil {
+		return nil, err
 	}
 
 	cacheTTL := 60 * time.Second
@@ -41,15 +84,101 @@ func New(dep Dependency) error {
 
 	cacheStore := cache.New(usecase.CloneFlightsOutput)
 
+	defaultCurrency := "IDR"
+	if code := dep.Config.GetString("modules.book-cabin.currency.default"); code != "" {
+		defaultCurrency = strings.ToUpper(code)
+	}
+	ratesPath := "mocks/exchange_rates.json"
+	if path := dep.Config.GetString("modules.book-cabin.currency.rates_path"); path != "" {
+		ratesPath = path
+	}
+	rates, err := currency.NewFileRates(ratesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The deadline is opt-in: by default every provider gets its full timeout,
+	// retries included, before the search answers.
+	var searchDeadline time.Duration
+	if deadlineMs := dep.Config.GetInt("modules.book-cabin.search.deadline_ms"); deadlineMs > 0 {
+		searchDeadline = time.Duration(deadlineMs) * time.Millisecond
+	}
+
+	var hedge *usecase.HedgeOptions
+	if percentile := dep.Config.GetFloat("modules.book-cabin.search.hedge.percentile"); percentile > 0 && percentile < 1 {
+		hedge = &usecase.HedgeOptions{Percentile: percentile, MinSamples: 20, WindowSize: 100}
+		if minSamples := dep.Config.GetInt("modules.book-cabin.search.hedge.min_samples"); minSamples > 0 {
+			hedge.MinSamples = int(minSamples)
+		}
+		if windowSize := dep.Config.GetInt("modules.book-cabin.search.hedge.window_size"); windowSize > 0 {
+			hedge.WindowSize = int(windowSize)
+		}
+	}
+
+	searchJobs := usecase.SearchJobOptions{Workers: 4, QueueSize: 32, TTL: 10 * time.Minute}
+	if workers := dep.Config.GetInt("modules.book-cabin.search_jobs.workers"); workers > 0 {
+		searchJobs.Workers = int(workers)
+	}
+	if queueSize := dep.Config.GetInt("modules.book-cabin.search_jobs.queue_size"); queueSize > 0 {
+		searchJobs.QueueSize = int(queueSize)
+	}
+	if ttlSeconds := dep.Config.GetInt("modules.book-cabin.search_jobs.ttl_seconds"); ttlSeconds > 0 {
+		searchJobs.TTL = time.Duration(ttlSeconds) * time.Second
+	}
+
+	scoreWeights, err := loadScoreWeights(dep.Config, "modules.book-cabin.scoring.weights")
+	if err != nil {
+		return nil, err
+	}
+
+	calendarConcurrency := 3
+	if concurrency := dep.Config.GetInt("modules.book-cabin.calendar.concurrency"); concurrency > 0 {
+		calendarConcurrency = int(concurrency)
+	}
+
 	uc := usecase.New(usecase.Dependency{
-		Providers:          providers,
-		Cache:              cacheStore,
-		CacheTTL:           cacheTTL,
-		ProviderTimeout:    1 * time.Second,
-		MaxProviderRetries: 2,
+		Providers:           providers,
+		ProviderOptions:     providerOptions,
+		Cache:               cacheStore,
+		CacheTTL:            cacheTTL,
+		ProviderTimeout:     defaults.timeout,
+		MaxProviderRetries:  defaults.retries,
+		Rates:               rates,
+		DefaultCurrency:     defaultCurrency,
+		SearchDeadline:      searchDeadline,
+		Hedge:               hedge,
+		IDs:                 dep.UUID,
+		SearchJobs:          searchJobs,
+		CalendarConcurrency: calendarConcurrency,
+		Airports:            airports,
+		Airlines:            airlines,
+		ScoreWeights:        scoreWeights,
 	})
 
 	inbound.RegisterHTTPEndpoint(dep.Router, uc)
 
-	return nil
+	return &Module{uc: uc}, nil
+}
+
+// loadScoreWeights reads the best value weights by factor name. Listing any
+// weight replaces the defaults, so unlisted factors do not count.
+func loadScoreWeights(cfg pkgconfig.Config, key string) (usecase.ScoreWeights, error) {
+	names := cfg.GetKeys(key)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	weights := make(usecase.ScoreWeights, len(names))
+	for _, name := range names {
+		weight := cfg.GetFloat(key + "." + name)
+		if !usecase.IsScoreFactor(name) || weight < 0 {
+			return nil, fmt.Errorf("%s: %w: %s", key, usecase.ErrInvalidScoreWeight, name)
+		}
+		weights[name] = weight
+	}
+	return weights, nil
+}
+
+// Close drains the background search jobs.
+func (m *Module) Close(ctx context.Context) error {
+	return m.uc.Close(ctx)
 }
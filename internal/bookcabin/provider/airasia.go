@@ -5,45 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
 type AirAsiaProvider struct {
-	path string
-	rng  *SafeRand
+	transport Transport
+	opts      Options
 }
 
-func NewAirAsiaProvider(path string) *AirAsiaProvider {
-	return &AirAsiaProvider{path: path, rng: NewSafeRand()}
+func NewAirAsiaProvider(transport Transport, opts Options) *AirAsiaProvider {
+	return &AirAsiaProvider{transport: transport, opts: opts}
 }
 
 func (a *AirAsiaProvider) Name() string {
 	return "AirAsia"
 }
 
-func (a *AirAsiaProvider) Search(ctx context.Context, _ SearchRequest) ([]entity.Flight, error) {
-	delay := time.Duration(50+a.rng.Intn(101)) * time.Millisecond
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(delay):
-	}
-
-	if a.rng.Float64() > 0.9 {
-		return nil, ErrTemporary
-	}
-
-	path := filepath.Clean(a.path)
-	data, err := os.ReadFile(path)
+func (a *AirAsiaProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
+	data, err := a.transport.Fetch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("airasia read file: %w", err)
+		return nil, fmt.Errorf("airasia fetch: %w", err)
 	}
+	return a.decode(data, req)
+}
 
+func (a *AirAsiaProvider) decode(data []byte, req SearchRequest) ([]entity.Flight, error) {
 	var resp struct {
 		Status  string `json:"status"`
 		Flights []struct {
@@ -67,16 +55,16 @@ func (a *AirAsiaProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 	}
 
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("airasia decode: %w", err)
+		return nil, fmt.Errorf("airasia: %w: %w", ErrDecode, err)
 	}
 
 	flights := make([]entity.Flight, 0, len(resp.Flights))
 	for _, f := range resp.Flights {
-		departAt, err := time.Parse(time.RFC3339, f.DepartTime)
+		departAt, err := a.opts.parseLocalTime(f.DepartTime, "", f.FromAirport)
 		if err != nil {
 			return nil, fmt.Errorf("airasia departure time: %w", err)
 		}
-		arriveAt, err := time.Parse(time.RFC3339, f.ArriveTime)
+		arriveAt, err := a.opts.parseLocalTime(f.ArriveTime, "", f.ToAirport)
 		if err != nil {
 			return nil, fmt.Errorf("airasia arrival time: %w", err)
 		}
@@ -91,21 +79,38 @@ func (a *AirAsiaProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 
 		carryOn, checked := splitAirAsiaBaggage(f.BaggageNote)
 
+		stopovers := make([]stopover, 0, len(f.Stops))
+		for _, stop := range f.Stops {
+			stopovers = append(stopovers, stopover{Airport: stop.Airport, LayoverMinute: stop.WaitTimeMinutes})
+		}
+
+		departure := entity.FlightPoint{Airport: f.FromAirport, City: a.opts.city(f.FromAirport), Time: departAt}
+		arrival := entity.FlightPoint{Airport: f.ToAirport, City: a.opts.city(f.ToAirport), Time: arriveAt}
+
+		marketing := a.opts.airline(carrierCode(f.FlightCode), f.Airline)
+
 		duration := durationMinutes(departAt, arriveAt, int(math.Round(f.DurationHours*60)))
 		flights = append(flights, entity.Flight{
-			ID:             fmt.Sprintf("%s_%s", f.FlightCode, a.Name()),
-			Provider:       a.Name(),
-			Airline:        entity.Airline{Name: f.Airline, Code: strings.ToUpper(f.FlightCode[:2])},
-			FlightNumber:   f.FlightCode,
-			Departure:      entity.FlightPoint{Airport: f.FromAirport, City: cityFromAirport(f.FromAirport), Time: departAt},
-			Arrival:        entity.FlightPoint{Airport: f.ToAirport, City: cityFromAirport(f.ToAirport), Time: arriveAt},
-			DurationMinute: duration,
-			Stops:          stops,
-			Price:          entity.Price{Amount: f.PriceIDR, Currency: "IDR"},
+			ID:                    fmt.Sprintf("%s_%s", f.FlightCode, a.Name()),
+			Provider:              a.Name(),
+			Airline:               marketing,
+			FlightNumber:          f.FlightCode,
+			OperatingAirline:      marketing,
+			OperatingFlightNumber: f.FlightCode,
+			Departure:             departure,
+			Arrival:               arrival,
+			DurationMinute:        duration,
+			Stops:                 stops,
+			Segments:              buildSegments(f.FlightCode, departure, arrival, stopovers, a.opts),
+			Price: entity.Price{
+				Amount:   f.PriceIDR,
+				Currency: "IDR",
+				Fares:    passengerFares(f.PriceIDR, req.Passengers, a.opts.FareRatio),
+			},
 			AvailableSeats: f.Seats,
 			CabinClass:     strings.ToLower(f.CabinClass),
 			Amenities:      []string{},
-			Baggage:        entity.Baggage{CarryOn: carryOn, Checked: checked},
+			Baggage:        entity.Baggage{CarryOn: baggageAllowance(carryOn), Checked: baggageAllowance(checked)},
 		})
 	}
 
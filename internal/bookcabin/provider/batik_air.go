@@ -4,56 +4,58 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
 type BatikAirProvider struct {
-	path string
-	rng  *SafeRand
+	transport Transport
+	opts      Options
 }
 
-func NewBatikAirProvider(path string) *BatikAirProvider {
-	return &BatikAirProvider{path: path, rng: NewSafeRand()}
+func NewBatikAirProvider(transport Transport, opts Options) *BatikAirProvider {
+	return &BatikAirProvider{transport: transport, opts: opts}
 }
 
 func (b *BatikAirProvider) Name() string {
 	return "Batik Air"
 }
 
-func (b *BatikAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity.Flight, error) {
-	delay := time.Duration(200+b.rng.Intn(201)) * time.Millisecond
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(delay):
-	}
-
-	path := filepath.Clean(b.path)
-	data, err := os.ReadFile(path)
+func (b *BatikAirProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
+	data, err := b.transport.Fetch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("batik air read file: %w", err)
+		return nil, fmt.Errorf("batik air fetch: %w", err)
 	}
+	return b.decode(data, req)
+}
 
+func (b *BatikAirProvider) decode(data []byte, req SearchRequest) ([]entity.Flight, error) {
 	var resp struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
 		Results []struct {
-			FlightNumber      string `json:"flightNumber"`
-			AirlineName       string `json:"airlineName"`
-			AirlineIATA       string `json:"airlineIATA"`
+			FlightNumber     string `json:"flightNumber"`
+			AirlineName      string `json:"airlineName"`
+			AirlineIATA      string `json:"airlineIATA"`
+			OperatingCarrier struct {
+				IATA         string `json:"iata"`
+				FlightNumber string `json:"flightNumber"`
+			} `json:"operatingCarrier"`
 			Origin            string `json:"origin"`
 			Destination       string `json:"destination"`
 			DepartureDateTime string `json:"departureDateTime"`
 			ArrivalDateTime   string `json:"arrivalDateTime"`
 			TravelTime        string `json:"travelTime"`
 			NumberOfStops     int    `json:"numberOfStops"`
-			Fare              struct {
+			Connections       []struct {
+				StopAirport  string `json:"stopAirport"`
+				StopDuration string `json:"stopDuration"`
+			} `json:"connections"`
+			Fare struct {
+				BasePrice    int    `json:"basePrice"`
+				Taxes        int    `json:"taxes"`
 				TotalPrice   int    `json:"totalPrice"`
 				CurrencyCode string `json:"currencyCode"`
 				Class        string `json:"class"`
@@ -66,16 +68,16 @@ func (b *BatikAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entit
 	}
 
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("batik air decode: %w", err)
+		return nil, fmt.Errorf("batik air: %w: %w", ErrDecode, err)
 	}
 
 	flights := make([]entity.Flight, 0, len(resp.Results))
 	for _, f := range resp.Results {
-		departAt, err := parseTimeWithLayout(f.DepartureDateTime, "2006-01-02T15:04:05-0700")
+		departAt, err := b.opts.parseLocalTime(f.DepartureDateTime, "", f.Origin)
 		if err != nil {
 			return nil, fmt.Errorf("batik air departure time: %w", err)
 		}
-		arriveAt, err := parseTimeWithLayout(f.ArrivalDateTime, "2006-01-02T15:04:05-0700")
+		arriveAt, err := b.opts.parseLocalTime(f.ArrivalDateTime, "", f.Destination)
 		if err != nil {
 			return nil, fmt.Errorf("batik air arrival time: %w", err)
 		}
@@ -88,22 +90,42 @@ func (b *BatikAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entit
 
 		carryOn, checked := splitBatikBaggage(f.BaggageInfo)
 
+		stopovers := make([]stopover, 0, len(f.Connections))
+		for _, conn := range f.Connections {
+			stopovers = append(stopovers, stopover{Airport: conn.StopAirport, LayoverMinute: parseDurationMinutes(conn.StopDuration)})
+		}
+
+		departure := entity.FlightPoint{Airport: f.Origin, City: b.opts.city(f.Origin), Time: departAt}
+		arrival := entity.FlightPoint{Airport: f.Destination, City: b.opts.city(f.Destination), Time: arriveAt}
+
+		marketing := b.opts.airline(f.AirlineIATA, f.AirlineName)
+		operating, operatingNumber := b.opts.operatingCarrier(marketing, f.FlightNumber, f.OperatingCarrier.IATA, f.OperatingCarrier.FlightNumber)
+
 		duration := durationMinutes(departAt, arriveAt, parseDurationMinutes(f.TravelTime))
 		flights = append(flights, entity.Flight{
-			ID:             fmt.Sprintf("%s_%s", f.FlightNumber, b.Name()),
-			Provider:       b.Name(),
-			Airline:        entity.Airline{Name: f.AirlineName, Code: f.AirlineIATA},
-			FlightNumber:   f.FlightNumber,
-			Departure:      entity.FlightPoint{Airport: f.Origin, City: cityFromAirport(f.Origin), Time: departAt},
-			Arrival:        entity.FlightPoint{Airport: f.Destination, City: cityFromAirport(f.Destination), Time: arriveAt},
-			DurationMinute: duration,
-			Stops:          f.NumberOfStops,
-			Price:          entity.Price{Amount: f.Fare.TotalPrice, Currency: f.Fare.CurrencyCode},
+			ID:                    fmt.Sprintf("%s_%s", f.FlightNumber, b.Name()),
+			Provider:              b.Name(),
+			Airline:               marketing,
+			FlightNumber:          f.FlightNumber,
+			OperatingAirline:      operating,
+			OperatingFlightNumber: operatingNumber,
+			Departure:             departure,
+			Arrival:               arrival,
+			DurationMinute:        duration,
+			Stops:                 f.NumberOfStops,
+			Segments:              buildSegments(f.FlightNumber, departure, arrival, stopovers, b.opts),
+			Price: entity.Price{
+				Amount:    f.Fare.TotalPrice,
+				Currency:  f.Fare.CurrencyCode,
+				Breakdown: batikFareBreakdown(f.Fare.BasePrice, f.Fare.Taxes, f.Fare.TotalPrice),
+				Fares:     passengerFares(f.Fare.TotalPrice, req.Passengers, b.opts.FareRatio),
+			},
 			AvailableSeats: f.SeatsAvailable,
-			CabinClass:     strings.ToLower(f.Fare.Class),
+			CabinClass:     cabinForFareClass(f.Fare.Class),
+			FareClass:      strings.ToUpper(strings.TrimSpace(f.Fare.Class)),
 			Aircraft:       aircraftPtr,
-			Amenities:      append([]string{}, f.Services...),
-			Baggage:        entity.Baggage{CarryOn: carryOn, Checked: checked},
+			Amenities:      normalizeAmenities(f.Services),
+			Baggage:        entity.Baggage{CarryOn: baggageAllowance(carryOn), Checked: baggageAllowance(checked)},
 		})
 	}
 
@@ -111,21 +133,36 @@ func (b *BatikAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entit
 }
 
 func parseDurationMinutes(value string) int {
-	re := regexp.MustCompile(`(?i)(\d+)h\s*(\d+)m`)
+	re := regexp.MustCompile(`(?i)^\s*(?:(\d+)h)?\s*(?:(\d+)m)?\s*$`)
 	matches := re.FindStringSubmatch(value)
-	if len(matches) != 3 {
+	if len(matches) != 3 || (matches[1] == "" && matches[2] == "") {
 		return 0
 	}
 	var hours, minutes int
-	if _, err := fmt.Sscanf(matches[1], "%d", &hours); err != nil {
-		return 0
+	if matches[1] != "" {
+		if _, err := fmt.Sscanf(matches[1], "%d", &hours); err != nil {
+			return 0
+		}
 	}
-	if _, err := fmt.Sscanf(matches[2], "%d", &minutes); err != nil {
-		return 0
+	if matches[2] != "" {
+		if _, err := fmt.Sscanf(matches[2], "%d", &minutes); err != nil {
+			return 0
+		}
 	}
 	return hours*60 + minutes
 }
 
+func batikFareBreakdown(basePrice, taxes, totalPrice int) *entity.PriceBreakdown {
+	if basePrice <= 0 {
+		return nil
+	}
+	fees := totalPrice - basePrice - taxes
+	if fees < 0 {
+		fees = 0
+	}
+	return &entity.PriceBreakdown{BaseFare: basePrice, Taxes: taxes, Fees: fees}
+}
+
 func splitBatikBaggage(value string) (string, string) {
 	parts := strings.Split(value, ",")
 	if len(parts) == 0 {
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+var ErrCircuitOpen = errors.New("provider circuit open")
+
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+type CircuitBreakerOptions struct {
+	FailureThreshold int
+	CoolDown         time.Duration
+}
+
+type BreakerStatus struct {
+	Provider            string
+	State               BreakerState
+	ConsecutiveFailures int
+	FailureThreshold    int
+	CoolDown            time.Duration
+	OpenedAt            *time.Time
+	RetryAt             *time.Time
+}
+
+type CircuitBreakerProvider struct {
+	provider Provider
+	opts     CircuitBreakerOptions
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreakerProvider(p Provider, opts CircuitBreakerOptions) *CircuitBreakerProvider {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 1
+	}
+	return &CircuitBreakerProvider{
+		provider: p,
+		opts:     opts,
+		now:      time.Now,
+		state:    BreakerClosed,
+	}
+}
+
+func (c *CircuitBreakerProvider) Name() string {
+	return c.provider.Name()
+}
+
+func (c *CircuitBreakerProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
+	if !c.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	flights, err := c.provider.Search(ctx, req)
+	c.record(err)
+	return flights, err
+}
+
+func (c *CircuitBreakerProvider) BreakerStatus() BreakerStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance()
+	status := BreakerStatus{
+		Provider:            c.provider.Name(),
+		State:               c.state,
+		ConsecutiveFailures: c.failures,
+		FailureThreshold:    c.opts.FailureThreshold,
+		CoolDown:            c.opts.CoolDown,
+	}
+	if c.state != BreakerClosed {
+		openedAt := c.openedAt
+		retryAt := c.openedAt.Add(c.opts.CoolDown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+func (c *CircuitBreakerProvider) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance()
+	switch c.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if c.probing {
+			return false
+		}
+		c.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (c *CircuitBreakerProvider) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The caller giving up says nothing about the provider's health, and
+	// neither does running out of time while queued on our own rate limiter.
+	if errors.Is(err, context.Canceled) || errors.Is(err, errLocalRateLimit) {
+		c.probing = false
+		return
+	}
+
+	if err == nil {
+		c.state = BreakerClosed
+		c.failures = 0
+		c.probing = false
+		return
+	}
+
+	c.failures++
+	if c.state == BreakerHalfOpen || c.failures >= c.opts.FailureThreshold {
+		c.state = BreakerOpen
+		c.openedAt = c.now()
+	}
+	c.probing = false
+}
+
+// advance moves an open breaker to half-open once the cool-down has elapsed.
+// Callers must hold c.mu.
+func (c *CircuitBreakerProvider) advance() {
+	if c.state == BreakerOpen && !c.now().Before(c.openedAt.Add(c.opts.CoolDown)) {
+		c.state = BreakerHalfOpen
+		c.probing = false
+	}
+}
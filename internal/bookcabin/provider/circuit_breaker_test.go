@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+type stubProvider struct {
+	err   error
+	calls int
+}
+
+func (s *stubProvider) Name() string {
+	return "Stub"
+}
+
+func (s *stubProvider) Search(_ context.Context, _ SearchRequest) ([]entity.Flight, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []entity.Flight{{ID: "stub"}}, nil
+}
+
+func newTestBreaker(p Provider, threshold int, coolDown time.Duration) (*CircuitBreakerProvider, *time.Time) {
+	now := time.Date(2025, 12, 15, 8, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreakerProvider(p, CircuitBreakerOptions{FailureThreshold: threshold, CoolDown: coolDown})
+	cb.now = func() time.Time { return now }
+	return cb, &now
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	stub := &stubProvider{err: ErrTemporary}
+	cb, _ := newTestBreaker(stub, 2, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := cb.Search(ctx, SearchRequest{}); !errors.Is(err, ErrTemporary) {
+			t.Fatalf("attempt %d: expected ErrTemporary, got %v", i, err)
+		}
+	}
+	if state := cb.BreakerStatus().State; state != BreakerOpen {
+		t.Fatalf("expected open breaker, got %s", state)
+	}
+
+	if _, err := cb.Search(ctx, SearchRequest{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("expected open breaker to skip the provider, got %d calls", stub.calls)
+	}
+}
+
+func TestCircuitBreakerHalfOpenRecovers(t *testing.T) {
+	stub := &stubProvider{err: ErrTemporary}
+	cb, now := newTestBreaker(stub, 1, time.Minute)
+	ctx := context.Background()
+
+	_, _ = cb.Search(ctx, SearchRequest{})
+	*now = now.Add(time.Minute)
+
+	status := cb.BreakerStatus()
+	if status.State != BreakerHalfOpen {
+		t.Fatalf("expected half-open breaker after cool-down, got %s", status.State)
+	}
+	if status.RetryAt == nil || !status.RetryAt.Equal(*now) {
+		t.Fatalf("unexpected retry_at: %v", status.RetryAt)
+	}
+
+	stub.err = nil
+	flights, err := cb.Search(ctx, SearchRequest{})
+	if err != nil || len(flights) != 1 {
+		t.Fatalf("expected probe to succeed, got %v (%d flights)", err, len(flights))
+	}
+	status = cb.BreakerStatus()
+	if status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("expected closed breaker after successful probe, got %+v", status)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	stub := &stubProvider{err: ErrTemporary}
+	cb, now := newTestBreaker(stub, 3, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, _ = cb.Search(ctx, SearchRequest{})
+	}
+	*now = now.Add(time.Minute)
+
+	if _, err := cb.Search(ctx, SearchRequest{}); !errors.Is(err, ErrTemporary) {
+		t.Fatalf("expected probe to reach the provider, got %v", err)
+	}
+	status := cb.BreakerStatus()
+	if status.State != BreakerOpen {
+		t.Fatalf("expected failed probe to reopen the breaker, got %s", status.State)
+	}
+	if status.OpenedAt == nil || !status.OpenedAt.Equal(*now) {
+		t.Fatalf("expected cool-down to restart, got opened_at %v", status.OpenedAt)
+	}
+}
+
+func TestCircuitBreakerIgnoresCanceledCalls(t *testing.T) {
+	stub := &stubProvider{err: context.Canceled}
+	cb, _ := newTestBreaker(stub, 1, time.Minute)
+
+	_, _ = cb.Search(context.Background(), SearchRequest{})
+	if status := cb.BreakerStatus(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("expected canceled call to be ignored, got %+v", status)
+	}
+}
+
+func TestCircuitBreakerIgnoresLocalRateLimit(t *testing.T) {
+	stub := &stubProvider{}
+	limited := NewRateLimitedProvider(stub, time.Hour)
+	cb, _ := newTestBreaker(limited, 1, time.Minute)
+
+	if _, err := cb.Search(context.Background(), SearchRequest{}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cb.Search(ctx, SearchRequest{}); !errors.Is(err, ErrRateLimited) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected rate limit timeout, got %v", err)
+	}
+	if status := cb.BreakerStatus(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("expected rate limit wait to be ignored, got %+v", status)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("expected the throttled call not to reach the provider, got %d calls", stub.calls)
+	}
+}
+
+func TestCircuitBreakerCountsUpstreamRateLimit(t *testing.T) {
+	stub := &stubProvider{err: fmt.Errorf("%w: %w: status 429", ErrTemporary, ErrRateLimited)}
+	cb, _ := newTestBreaker(stub, 1, time.Minute)
+
+	_, _ = cb.Search(context.Background(), SearchRequest{})
+	if state := cb.BreakerStatus().State; state != BreakerOpen {
+		t.Fatalf("expected a provider rate limit to count as a failure, got %s", state)
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	stub := &stubProvider{err: ErrTemporary}
+	cb, _ := newTestBreaker(stub, 2, time.Minute)
+	ctx := context.Background()
+
+	_, _ = cb.Search(ctx, SearchRequest{})
+	stub.err = nil
+	_, _ = cb.Search(ctx, SearchRequest{})
+	stub.err = ErrTemporary
+	_, _ = cb.Search(ctx, SearchRequest{})
+
+	if status := cb.BreakerStatus(); status.State != BreakerClosed || status.ConsecutiveFailures != 1 {
+		t.Fatalf("expected non-consecutive failures to keep the breaker closed, got %+v", status)
+	}
+}
@@ -4,48 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
 type GarudaIndonesiaProvider struct {
-	path string
-	rng  *SafeRand
+	transport Transport
+	opts      Options
 }
 
-func NewGarudaIndonesiaProvider(path string) *GarudaIndonesiaProvider {
-	return &GarudaIndonesiaProvider{path: path, rng: NewSafeRand()}
+func NewGarudaIndonesiaProvider(transport Transport, opts Options) *GarudaIndonesiaProvider {
+	return &GarudaIndonesiaProvider{transport: transport, opts: opts}
 }
 
 func (g *GarudaIndonesiaProvider) Name() string {
 	return "Garuda Indonesia"
 }
 
-func (g *GarudaIndonesiaProvider) Search(ctx context.Context, _ SearchRequest) ([]entity.Flight, error) {
-	delay := time.Duration(50+g.rng.Intn(51)) * time.Millisecond
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(delay):
-	}
-
-	path := filepath.Clean(g.path)
-	data, err := os.ReadFile(path)
+func (g *GarudaIndonesiaProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
+	data, err := g.transport.Fetch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("garuda read file: %w", err)
+		return nil, fmt.Errorf("garuda fetch: %w", err)
 	}
+	return g.decode(data, req)
+}
 
+func (g *GarudaIndonesiaProvider) decode(data []byte, req SearchRequest) ([]entity.Flight, error) {
 	var resp struct {
 		Status  string `json:"status"`
 		Flights []struct {
 			FlightID    string `json:"flight_id"`
 			Airline     string `json:"airline"`
 			AirlineCode string `json:"airline_code"`
-			Departure   struct {
+			OperatedBy  struct {
+				AirlineCode  string `json:"airline_code"`
+				FlightNumber string `json:"flight_number"`
+			} `json:"operated_by"`
+			Departure struct {
 				Airport string `json:"airport"`
 				City    string `json:"city"`
 				Time    string `json:"time"`
@@ -86,16 +82,16 @@ func (g *GarudaIndonesiaProvider) Search(ctx context.Context, _ SearchRequest) (
 	}
 
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("garuda decode: %w", err)
+		return nil, fmt.Errorf("garuda: %w: %w", ErrDecode, err)
 	}
 
 	flights := make([]entity.Flight, 0, len(resp.Flights))
 	for _, f := range resp.Flights {
-		departAt, err := time.Parse(time.RFC3339, f.Departure.Time)
+		departAt, err := g.opts.parseLocalTime(f.Departure.Time, "", f.Departure.Airport)
 		if err != nil {
 			return nil, fmt.Errorf("garuda departure time: %w", err)
 		}
-		arriveAt, err := time.Parse(time.RFC3339, f.Arrival.Time)
+		arriveAt, err := g.opts.parseLocalTime(f.Arrival.Time, "", f.Arrival.Airport)
 		if err != nil {
 			return nil, fmt.Errorf("garuda arrival time: %w", err)
 		}
@@ -107,14 +103,8 @@ func (g *GarudaIndonesiaProvider) Search(ctx context.Context, _ SearchRequest) (
 		}
 
 		baggage := entity.Baggage{
-			CarryOn: fmt.Sprintf("%d piece", f.Baggage.CarryOn),
-			Checked: fmt.Sprintf("%d piece", f.Baggage.Checked),
-		}
-		if f.Baggage.CarryOn > 1 {
-			baggage.CarryOn = fmt.Sprintf("%d pieces", f.Baggage.CarryOn)
-		}
-		if f.Baggage.Checked > 1 {
-			baggage.Checked = fmt.Sprintf("%d pieces", f.Baggage.Checked)
+			CarryOn: pieceAllowance(f.Baggage.CarryOn),
+			Checked: pieceAllowance(f.Baggage.Checked),
 		}
 
 		stops := f.Stops
@@ -122,21 +112,60 @@ func (g *GarudaIndonesiaProvider) Search(ctx context.Context, _ SearchRequest) (
 			stops = len(f.Segments) - 1
 		}
 
-		duration := durationMinutes(departAt, arriveAt, f.DurationMinutes)
+		departure := entity.FlightPoint{Airport: f.Departure.Airport, City: f.Departure.City, Time: departAt}
+		arrival := entity.FlightPoint{Airport: f.Arrival.Airport, City: f.Arrival.City, Time: arriveAt}
+
+		segments := buildSegments(f.FlightID, departure, arrival, nil, g.opts)
+		if len(f.Segments) > 0 {
+			segments = make([]entity.Segment, 0, len(f.Segments))
+			for _, seg := range f.Segments {
+				segDepartAt, err := g.opts.parseLocalTime(seg.Departure.Time, "", seg.Departure.Airport)
+				if err != nil {
+					return nil, fmt.Errorf("garuda segment departure time: %w", err)
+				}
+				segArriveAt, err := g.opts.parseLocalTime(seg.Arrival.Time, "", seg.Arrival.Airport)
+				if err != nil {
+					return nil, fmt.Errorf("garuda segment arrival time: %w", err)
+				}
+				segments = append(segments, entity.Segment{
+					FlightNumber:   seg.FlightNumber,
+					Departure:      entity.FlightPoint{Airport: seg.Departure.Airport, City: g.opts.city(seg.Departure.Airport), Time: segDepartAt},
+					Arrival:        entity.FlightPoint{Airport: seg.Arrival.Airport, City: g.opts.city(seg.Arrival.Airport), Time: segArriveAt},
+					DurationMinute: durationMinutes(segDepartAt, segArriveAt, seg.DurationMinutes),
+					LayoverMinute:  seg.LayoverMinutes,
+				})
+			}
+			// The flight-level arrival only covers the first segment when the
+			// provider sends segments, so the final segment is authoritative.
+			last := segments[len(segments)-1]
+			arrival = entity.FlightPoint{Airport: last.Arrival.Airport, City: g.opts.city(last.Arrival.Airport), Time: last.Arrival.Time}
+		}
+
+		marketing := g.opts.airline(f.AirlineCode, f.Airline)
+		operating, operatingNumber := g.opts.operatingCarrier(marketing, f.FlightID, f.OperatedBy.AirlineCode, f.OperatedBy.FlightNumber)
+
+		duration := durationMinutes(departure.Time, arrival.Time, f.DurationMinutes)
 		flights = append(flights, entity.Flight{
-			ID:             fmt.Sprintf("%s_%s", f.FlightID, g.Name()),
-			Provider:       g.Name(),
-			Airline:        entity.Airline{Name: f.Airline, Code: f.AirlineCode},
-			FlightNumber:   f.FlightID,
-			Departure:      entity.FlightPoint{Airport: f.Departure.Airport, City: f.Departure.City, Time: departAt},
-			Arrival:        entity.FlightPoint{Airport: f.Arrival.Airport, City: f.Arrival.City, Time: arriveAt},
-			DurationMinute: duration,
-			Stops:          stops,
-			Price:          entity.Price{Amount: f.Price.Amount, Currency: f.Price.Currency},
+			ID:                    fmt.Sprintf("%s_%s", f.FlightID, g.Name()),
+			Provider:              g.Name(),
+			Airline:               marketing,
+			FlightNumber:          f.FlightID,
+			OperatingAirline:      operating,
+			OperatingFlightNumber: operatingNumber,
+			Departure:             departure,
+			Arrival:               arrival,
+			DurationMinute:        duration,
+			Stops:                 stops,
+			Segments:              segments,
+			Price: entity.Price{
+				Amount:   f.Price.Amount,
+				Currency: f.Price.Currency,
+				Fares:    passengerFares(f.Price.Amount, req.Passengers, g.opts.FareRatio),
+			},
 			AvailableSeats: f.AvailableSeats,
 			CabinClass:     strings.ToLower(f.FareClass),
 			Aircraft:       aircraftPtr,
-			Amenities:      append([]string{}, f.Amenities...),
+			Amenities:      normalizeAmenities(f.Amenities),
 			Baggage:        baggage,
 		})
 	}
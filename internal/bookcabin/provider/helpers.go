@@ -2,37 +2,217 @@ package provider
 
 import (
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
-func cityFromAirport(code string) string {
-	switch strings.ToUpper(code) {
-	case "CGK":
-		return "Jakarta"
-	case "DPS":
-		return "Denpasar"
-	case "SUB":
-		return "Surabaya"
-	case "UPG":
-		return "Makassar"
-	case "SOC":
-		return "Solo"
-	default:
+type stopover struct {
+	Airport       string
+	LayoverMinute int
+}
+
+// city looks the airport up in the configured directory. Providers that do
+// not send city names rely on it; unknown airports get an empty city.
+func (o Options) city(code string) string {
+	if o.Airports == nil {
 		return ""
 	}
+	a, ok := o.Airports.Airport(code)
+	if !ok {
+		return ""
+	}
+	return a.City
+}
+
+// airline normalizes the carrier a provider reports. The code is tried first,
+// then the name, so a provider sending only one of them still gets the
+// canonical record; unknown carriers keep what the provider sent.
+func (o Options) airline(code, name string) entity.Airline {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	name = strings.TrimSpace(name)
+	if o.Airlines != nil {
+		for _, key := range []string{code, name} {
+			if key == "" {
+				continue
+			}
+			if a, ok := o.Airlines.Airline(key); ok {
+				return fromRegistry(a)
+			}
+		}
+	}
+	return entity.Airline{Name: name, Code: code}
+}
+
+func fromRegistry(a airline.Airline) entity.Airline {
+	return entity.Airline{Name: a.Name, Code: a.Code, ICAO: a.ICAO, Type: a.Type, Alliance: a.Alliance}
+}
+
+// operatingCarrier returns the carrier flying a codeshare. Providers leave the
+// operating fields empty for flights they operate themselves, which then fly
+// under the marketing carrier and flight number.
+func (o Options) operatingCarrier(marketing entity.Airline, flightNumber, operatingCode, operatingFlightNumber string) (entity.Airline, string) {
+	operatingFlightNumber = strings.ToUpper(strings.TrimSpace(operatingFlightNumber))
+	if strings.TrimSpace(operatingCode) == "" {
+		operatingCode = carrierCode(operatingFlightNumber)
+	}
+	if operatingCode == "" {
+		return marketing, flightNumber
+	}
+	if operatingFlightNumber == "" {
+		operatingFlightNumber = flightNumber
+	}
+	return o.airline(operatingCode, ""), operatingFlightNumber
+}
+
+// carrierCode returns the two-character airline designator a flight number
+// starts with, e.g. QZ for QZ7250 or 8B for 8B123.
+func carrierCode(flightNumber string) string {
+	flightNumber = strings.TrimSpace(flightNumber)
+	if len(flightNumber) < 3 {
+		return ""
+	}
+	return strings.ToUpper(flightNumber[:2])
+}
+
+// baggageAllowance reads a free-text allowance such as "7kg cabin", "20 kg",
+// "2 pieces", "2x23kg" or "Additional fee". Text that states no amount, like
+// "Cabin baggage only", counts as included; a stated zero does not.
+func baggageAllowance(text string) entity.BaggageAllowance {
+	text = strings.TrimSpace(text)
+	allowance := entity.BaggageAllowance{Description: text}
+	lower := strings.ToLower(text)
+	if lower == "" || strings.Contains(lower, "fee") || strings.Contains(lower, "not included") || strings.Contains(lower, "none") {
+		return allowance
+	}
+
+	stated := false
+	for i := 0; i < len(lower); {
+		if lower[i] < '0' || lower[i] > '9' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(lower) && lower[j] >= '0' && lower[j] <= '9' {
+			j++
+		}
+		n, _ := strconv.Atoi(lower[i:j])
+		switch unit := strings.TrimLeft(lower[j:], " "); {
+		case strings.HasPrefix(unit, "kg"):
+			allowance.WeightKg, stated = n, true
+		case strings.HasPrefix(unit, "pc"), strings.HasPrefix(unit, "piece"), strings.HasPrefix(unit, "bag"), strings.HasPrefix(unit, "x"):
+			allowance.Pieces, stated = n, true
+		}
+		i = j
+	}
+	allowance.Included = !stated || allowance.Pieces > 0 || allowance.WeightKg > 0
+	return allowance
+}
+
+// pieceAllowance describes an allowance a provider sends as a piece count.
+func pieceAllowance(pieces int) entity.BaggageAllowance {
+	description := fmt.Sprintf("%d piece", pieces)
+	if pieces > 1 {
+		description += "s"
+	}
+	return entity.BaggageAllowance{Included: pieces > 0, Pieces: pieces, Description: description}
+}
+
+// normalizeAmenities maps the providers' amenity names onto the entity
+// vocabulary, dropping duplicates. Unknown amenities are kept lowercased.
+func normalizeAmenities(values []string) []string {
+	amenities := make([]string, 0, len(values))
+	for _, value := range values {
+		amenity := normalizeAmenity(value)
+		if amenity != "" && !slices.Contains(amenities, amenity) {
+			amenities = append(amenities, amenity)
+		}
+	}
+	return amenities
 }
 
-func parseTimeWithLayout(value, layout string) (time.Time, error) {
-	return time.Parse(layout, value)
+func normalizeAmenity(value string) string {
+	value = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(value)))
+	switch value {
+	case "wifi", "wi_fi", "internet":
+		return entity.AmenityWifi
+	case "meal", "meals", "hot_meal", "food":
+		return entity.AmenityMeal
+	case "entertainment", "ife", "inflight_entertainment", "in_flight_entertainment":
+		return entity.AmenityEntertainment
+	case "power", "power_outlet", "power_outlets", "usb", "usb_power":
+		return entity.AmenityPower
+	default:
+		return value
+	}
 }
 
-func parseTimeInLocation(value, layout, timezone string) (time.Time, error) {
-	loc, err := time.LoadLocation(timezone)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("load location %s: %w", timezone, err)
+func (o Options) location(code string) *time.Location {
+	if o.Airports == nil {
+		return nil
 	}
-	return time.ParseInLocation(layout, value, loc)
+	a, ok := o.Airports.Airport(code)
+	if !ok {
+		return nil
+	}
+	return a.Location
+}
+
+func offsetLayouts() []string {
+	return []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02 15:04:05-0700", "2006-01-02T15:04Z07:00"}
+}
+
+func localLayouts() []string {
+	return []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+}
+
+// parseLocalTime parses a provider timestamp and returns it in the local time
+// of the airport. An offset in the value wins; otherwise the value is read in
+// zone (an IANA name or a WIB/WITA/WIT label, which may also trail the value)
+// or, without one, in the airport's own zone.
+func (o Options) parseLocalTime(value, zone, airportCode string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if idx := strings.LastIndexByte(value, ' '); idx > 0 && airport.IsZoneLabel(value[idx+1:]) {
+		zone = value[idx+1:]
+		value = strings.TrimSpace(value[:idx])
+	}
+	local := o.location(airportCode)
+	toLocal := func(t time.Time) time.Time {
+		if local == nil {
+			return t
+		}
+		return t.In(local)
+	}
+
+	for _, layout := range offsetLayouts() {
+		if t, err := time.Parse(layout, value); err == nil {
+			return toLocal(t), nil
+		}
+	}
+
+	loc := local
+	if strings.TrimSpace(zone) != "" {
+		zoneLoc, err := airport.LoadLocation(zone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %w", ErrDecode, err)
+		}
+		loc = zoneLoc
+	}
+	if loc == nil {
+		return time.Time{}, fmt.Errorf("%w: no time zone for %q at %s", ErrDecode, value, airportCode)
+	}
+	for _, layout := range localLayouts() {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return toLocal(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: unsupported time %q", ErrDecode, value)
 }
 
 func durationMinutes(depart, arrive time.Time, fallback int) int {
@@ -45,3 +225,80 @@ func durationMinutes(depart, arrive time.Time, fallback int) int {
 	}
 	return diff
 }
+
+// cabinForFareClass maps a one-letter booking class onto its cabin; providers
+// sending a cabin name instead get it back lowercased.
+func cabinForFareClass(class string) string {
+	class = strings.TrimSpace(class)
+	if len(class) != 1 {
+		return strings.ToLower(class)
+	}
+	switch strings.ToUpper(class) {
+	case "F", "A", "P":
+		return "first"
+	case "J", "C", "D", "I", "Z", "R":
+		return "business"
+	case "W", "E":
+		return "premium_economy"
+	default:
+		return "economy"
+	}
+}
+
+func buildSegments(flightNumber string, departure, arrival entity.FlightPoint, stopovers []stopover, opts Options) []entity.Segment {
+	segments := make([]entity.Segment, 0, len(stopovers)+1)
+	from := departure
+	layover := 0
+	for _, stop := range stopovers {
+		to := entity.FlightPoint{Airport: stop.Airport, City: opts.city(stop.Airport)}
+		segments = append(segments, entity.Segment{
+			FlightNumber:  flightNumber,
+			Departure:     from,
+			Arrival:       to,
+			LayoverMinute: layover,
+		})
+		from = to
+		layover = stop.LayoverMinute
+	}
+	segments = append(segments, entity.Segment{
+		FlightNumber:  flightNumber,
+		Departure:     from,
+		Arrival:       arrival,
+		LayoverMinute: layover,
+	})
+	for i := range segments {
+		segments[i].DurationMinute = durationMinutes(segments[i].Departure.Time, segments[i].Arrival.Time, 0)
+	}
+	return segments
+}
+
+func passengerFares(adultAmount int, pax entity.Passengers, ratio FareRatio) []entity.PassengerFare {
+	if pax.Adults <= 0 {
+		pax.Adults = 1
+	}
+	fares := []entity.PassengerFare{{
+		Type:   entity.PassengerAdult,
+		Count:  pax.Adults,
+		Amount: adultAmount,
+		Total:  adultAmount * pax.Adults,
+	}}
+	if pax.Children > 0 {
+		amount := int(math.Round(float64(adultAmount) * ratio.Child))
+		fares = append(fares, entity.PassengerFare{
+			Type:   entity.PassengerChild,
+			Count:  pax.Children,
+			Amount: amount,
+			Total:  amount * pax.Children,
+		})
+	}
+	if pax.Infants > 0 {
+		amount := int(math.Round(float64(adultAmount) * ratio.Infant))
+		fares = append(fares, entity.PassengerFare{
+			Type:   entity.PassengerInfant,
+			Count:  pax.Infants,
+			Amount: amount,
+			Total:  amount * pax.Infants,
+		})
+	}
+	return fares
+}
@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func TestPassengerFares(t *testing.T) {
+	ratio := FareRatio{Child: 0.75, Infant: 0.1}
+	tests := []struct {
+		name string
+		pax  entity.Passengers
+		want []entity.PassengerFare
+	}{
+		{
+			name: "adults only",
+			pax:  entity.Passengers{Adults: 2},
+			want: []entity.PassengerFare{{Type: entity.PassengerAdult, Count: 2, Amount: 1000000, Total: 2000000}},
+		},
+		{
+			name: "missing adults count as one",
+			pax:  entity.Passengers{},
+			want: []entity.PassengerFare{{Type: entity.PassengerAdult, Count: 1, Amount: 1000000, Total: 1000000}},
+		},
+		{
+			name: "children and infants use their ratios",
+			pax:  entity.Passengers{Adults: 2, Children: 1, Infants: 1},
+			want: []entity.PassengerFare{
+				{Type: entity.PassengerAdult, Count: 2, Amount: 1000000, Total: 2000000},
+				{Type: entity.PassengerChild, Count: 1, Amount: 750000, Total: 750000},
+				{Type: entity.PassengerInfant, Count: 1, Amount: 100000, Total: 100000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := passengerFares(1000000, tt.pax, ratio); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPassengerFaresRounding(t *testing.T) {
+	got := passengerFares(999999, entity.Passengers{Adults: 1, Children: 3, Infants: 1}, FareRatio{Child: 0.75, Infant: 0.1})
+	if got[1].Amount != 749999 || got[1].Total != 2249997 {
+		t.Fatalf("expected the child fare rounded down to 749999, got %+v", got[1])
+	}
+	if got[2].Amount != 100000 {
+		t.Fatalf("expected the infant fare rounded up to 100000, got %+v", got[2])
+	}
+}
+
+func TestParseLocalTime(t *testing.T) {
+	registry, err := airport.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	opts := Options{Airports: registry}
+
+	tests := []struct {
+		name    string
+		value   string
+		zone    string
+		airport string
+		want    string
+	}{
+		{name: "offset converted to airport zone", value: "2025-12-15T06:00:00+07:00", airport: "DPS", want: "2025-12-15T07:00:00+08:00"},
+		{name: "compact offset", value: "2025-12-15T06:00:00+0700", airport: "CGK", want: "2025-12-15T06:00:00+07:00"},
+		{name: "iana zone name", value: "2025-12-15T06:00:00", zone: "Asia/Makassar", airport: "UPG", want: "2025-12-15T06:00:00+08:00"},
+		{name: "zone label", value: "2025-12-15T06:00:00", zone: "WIT", airport: "DJJ", want: "2025-12-15T06:00:00+09:00"},
+		{name: "trailing zone label", value: "2025-12-15 06:00 WIB", airport: "DPS", want: "2025-12-15T07:00:00+08:00"},
+		{name: "airport zone fallback", value: "2025-12-15T06:00:00", airport: "DPS", want: "2025-12-15T06:00:00+08:00"},
+		{name: "unknown airport keeps offset", value: "2025-12-15T06:00:00+09:00", airport: "XXX", want: "2025-12-15T06:00:00+09:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := opts.parseLocalTime(tt.value, tt.zone, tt.airport)
+			if err != nil {
+				t.Fatalf("parseLocalTime: %v", err)
+			}
+			if formatted := got.Format(time.RFC3339); formatted != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, formatted)
+			}
+		})
+	}
+}
+
+func TestParseLocalTimeErrors(t *testing.T) {
+	registry, err := airport.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	opts := Options{Airports: registry}
+
+	tests := []struct {
+		name    string
+		value   string
+		zone    string
+		airport string
+	}{
+		{name: "no zone for unknown airport", value: "2025-12-15T06:00:00", airport: "XXX"},
+		{name: "unknown zone", value: "2025-12-15T06:00:00", zone: "Mars/Base", airport: "CGK"},
+		{name: "bad format", value: "15/12/2025 06:00", airport: "CGK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := opts.parseLocalTime(tt.value, tt.zone, tt.airport); !errors.Is(err, ErrDecode) {
+				t.Fatalf("expected ErrDecode, got %v", err)
+			}
+		})
+	}
+}
+
+func TestOperatingCarrier(t *testing.T) {
+	airlines, err := airline.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	opts := Options{Airlines: airlines}
+	marketing := opts.airline("ID", "Batik Air")
+
+	tests := []struct {
+		name       string
+		code       string
+		number     string
+		wantCode   string
+		wantNumber string
+	}{
+		{name: "own flight", wantCode: "ID", wantNumber: "ID7742"},
+		{name: "code and number", code: "JT", number: "JT742", wantCode: "JT", wantNumber: "JT742"},
+		{name: "code from number", number: "jt742", wantCode: "JT", wantNumber: "JT742"},
+		{name: "code only", code: "JT", wantCode: "JT", wantNumber: "ID7742"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, number := opts.operatingCarrier(marketing, "ID7742", tt.code, tt.number)
+			if got.Code != tt.wantCode || number != tt.wantNumber {
+				t.Fatalf("expected %s %s, got %s %s", tt.wantCode, tt.wantNumber, got.Code, number)
+			}
+		})
+	}
+}
+
+func TestCabinForFareClass(t *testing.T) {
+	tests := map[string]string{
+		"Y":        "economy",
+		"m":        "economy",
+		"C":        "business",
+		"F":        "first",
+		"W":        "premium_economy",
+		"ECONOMY":  "economy",
+		"Business": "business",
+	}
+	for class, want := range tests {
+		if got := cabinForFareClass(class); got != want {
+			t.Fatalf("cabinForFareClass(%q): expected %s, got %s", class, want, got)
+		}
+	}
+}
+
+func TestBaggageAllowance(t *testing.T) {
+	tests := []struct {
+		text         string
+		wantIncluded bool
+		wantPieces   int
+		wantWeightKg int
+	}{
+		{text: "7kg cabin", wantIncluded: true, wantWeightKg: 7},
+		{text: "20 kg", wantIncluded: true, wantWeightKg: 20},
+		{text: "2 pieces", wantIncluded: true, wantPieces: 2},
+		{text: "2x23kg", wantIncluded: true, wantPieces: 2, wantWeightKg: 23},
+		{text: "Cabin baggage only", wantIncluded: true},
+		{text: "additional fee"},
+		{text: "0 piece"},
+		{text: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := baggageAllowance(tt.text)
+			if got.Included != tt.wantIncluded || got.Pieces != tt.wantPieces || got.WeightKg != tt.wantWeightKg {
+				t.Fatalf("expected included=%t pieces=%d kg=%d, got included=%t pieces=%d kg=%d",
+					tt.wantIncluded, tt.wantPieces, tt.wantWeightKg, got.Included, got.Pieces, got.WeightKg)
+			}
+			if got.Description != tt.text {
+				t.Fatalf("expected description %q, got %q", tt.text, got.Description)
+			}
+		})
+	}
+}
+
+func TestNormalizeAmenities(t *testing.T) {
+	got := normalizeAmenities([]string{"WiFi", "power_outlet", "Meal", "meals", "In-Flight Entertainment", "Snack"})
+	want := []string{"wifi", "power", "meal", "entertainment", "snack"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxResponseBytes = 10 << 20
+
+var ErrUnexpectedStatus = errors.New("unexpected provider status")
+
+type HTTPAuth struct {
+	Scheme string
+	Token  string
+}
+
+type HTTPTransportOptions struct {
+	BaseURL string
+	Headers map[string]string
+	Auth    HTTPAuth
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+type HTTPTransport struct {
+	baseURL *url.URL
+	headers map[string]string
+	auth    HTTPAuth
+	timeout time.Duration
+	client  *http.Client
+}
+
+func NewHTTPTransport(opts HTTPTransportOptions) (*HTTPTransport, error) {
+	baseURL, err := url.Parse(opts.BaseURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("%w: invalid base_url %q", ErrInvalidConfig, opts.BaseURL)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &HTTPTransport{
+		baseURL: baseURL,
+		headers: opts.Headers,
+		auth:    opts.Auth,
+		timeout: opts.Timeout,
+		client:  client,
+	}, nil
+}
+
+func (h *HTTPTransport) Fetch(ctx context.Context, req SearchRequest) ([]byte, error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	target := *h.baseURL
+	target.RawQuery = searchQuery(req).Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	for key, value := range h.headers {
+		httpReq.Header.Set(key, value)
+	}
+	if h.auth.Token != "" {
+		scheme := h.auth.Scheme
+		if scheme == "" {
+			scheme = "Bearer"
+		}
+		httpReq.Header.Set("Authorization", scheme+" "+h.auth.Token)
+	}
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("%w: %w", ErrTemporary, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: read body: %w", ErrTemporary, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w: %w: status %d", ErrTemporary, ErrRateLimited, resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%w: status %d", ErrTemporary, resp.StatusCode)
+	case resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices:
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+func searchQuery(req SearchRequest) url.Values {
+	q := url.Values{}
+	q.Set("origin", strings.ToUpper(req.Origin))
+	q.Set("destination", strings.ToUpper(req.Destination))
+	if !req.DepartureDate.IsZero() {
+		q.Set("departure_date", req.DepartureDate.Format("2006-01-02"))
+	}
+	if req.ReturnDate != nil {
+		q.Set("return_date", req.ReturnDate.Format("2006-01-02"))
+	}
+	q.Set("adults", strconv.Itoa(req.Passengers.Adults))
+	q.Set("children", strconv.Itoa(req.Passengers.Children))
+	q.Set("infants", strconv.Itoa(req.Passengers.Infants))
+	if req.CabinClass != "" {
+		q.Set("cabin_class", req.CabinClass)
+	}
+	return q
+}
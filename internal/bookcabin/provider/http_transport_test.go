@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func testSearchRequest() SearchRequest {
+	return SearchRequest{
+		Origin:        "CGK",
+		Destination:   "DPS",
+		DepartureDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC),
+		Passengers:    entity.Passengers{Adults: 2, Children: 1},
+		CabinClass:    "economy",
+	}
+}
+
+func fixtureServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "mocks", fixture))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPTransportSendsQueryHeadersAndAuth(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Clone(context.Background())
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	transport, err := NewHTTPTransport(HTTPTransportOptions{
+		BaseURL: srv.URL + "/v1/search",
+		Headers: map[string]string{"X-Api-Key": "secret"},
+		Auth:    HTTPAuth{Token: "token"},
+		Timeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPTransport: %v", err)
+	}
+
+	if _, err := transport.Fetch(context.Background(), testSearchRequest()); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	if got.URL.Path != "/v1/search" {
+		t.Fatalf("unexpected path: %s", got.URL.Path)
+	}
+	q := got.URL.Query()
+	expected := map[string]string{
+		"origin":         "CGK",
+		"destination":    "DPS",
+		"departure_date": "2025-12-15",
+		"adults":         "2",
+		"children":       "1",
+		"infants":        "0",
+		"cabin_class":    "economy",
+	}
+	for key, value := range expected {
+		if q.Get(key) != value {
+			t.Fatalf("query %s: expected %q, got %q", key, value, q.Get(key))
+		}
+	}
+	if q.Has("return_date") {
+		t.Fatalf("unexpected return_date: %q", q.Get("return_date"))
+	}
+	if got.Header.Get("X-Api-Key") != "secret" {
+		t.Fatalf("missing custom header, got %q", got.Header.Get("X-Api-Key"))
+	}
+	if got.Header.Get("Authorization") != "Bearer token" {
+		t.Fatalf("unexpected authorization: %q", got.Header.Get("Authorization"))
+	}
+}
+
+func TestHTTPTransportStatusErrors(t *testing.T) {
+	tests := []struct {
+		status    int
+		temporary bool
+	}{
+		{status: http.StatusTooManyRequests, temporary: true},
+		{status: http.StatusBadGateway, temporary: true},
+		{status: http.StatusServiceUnavailable, temporary: true},
+		{status: http.StatusBadRequest, temporary: false},
+		{status: http.StatusUnauthorized, temporary: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			transport, err := NewHTTPTransport(HTTPTransportOptions{BaseURL: srv.URL})
+			if err != nil {
+				t.Fatalf("NewHTTPTransport: %v", err)
+			}
+
+			_, err = transport.Fetch(context.Background(), testSearchRequest())
+			if err == nil {
+				t.Fatalf("expected error for status %d", tt.status)
+			}
+			if errors.Is(err, ErrTemporary) != tt.temporary {
+				t.Fatalf("status %d: expected temporary=%v, got %v", tt.status, tt.temporary, err)
+			}
+			if errors.Is(err, ErrRateLimited) != (tt.status == http.StatusTooManyRequests) {
+				t.Fatalf("status %d: unexpected rate limit classification: %v", tt.status, err)
+			}
+			if !tt.temporary && !errors.Is(err, ErrUnexpectedStatus) {
+				t.Fatalf("status %d: expected ErrUnexpectedStatus, got %v", tt.status, err)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	transport, err := NewHTTPTransport(HTTPTransportOptions{BaseURL: srv.URL, Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewHTTPTransport: %v", err)
+	}
+
+	_, err = transport.Fetch(context.Background(), testSearchRequest())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestNewHTTPTransportInvalidBaseURL(t *testing.T) {
+	if _, err := NewHTTPTransport(HTTPTransportOptions{BaseURL: "not a url"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestProviderDecodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"flights": "not-a-list"}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewRegistry().Build(Config{Type: TypeGarudaIndonesia, BaseURL: srv.URL}, Options{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := p.Search(context.Background(), testSearchRequest()); !errors.Is(err, ErrDecode) {
+		t.Fatalf("expected ErrDecode, got %v", err)
+	}
+}
+
+func TestProvidersOverHTTP(t *testing.T) {
+	tests := []struct {
+		typeName string
+		fixture  string
+		name     string
+	}{
+		{typeName: TypeGarudaIndonesia, fixture: "garuda_indonesia_search_response.json", name: "Garuda Indonesia"},
+		{typeName: TypeLionAir, fixture: "lion_air_search_response.json", name: "Lion Air"},
+		{typeName: TypeBatikAir, fixture: "batik_air_search_response.json", name: "Batik Air"},
+		{typeName: TypeAirAsia, fixture: "airasia_search_response.json", name: "AirAsia"},
+	}
+
+	registry := NewRegistry()
+	opts := Options{FareRatio: FareRatio{Child: 0.75, Infant: 0.1}}
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			srv := fixtureServer(t, tt.fixture)
+
+			p, err := registry.Build(Config{Type: tt.typeName, BaseURL: srv.URL, Timeout: time.Second}, opts)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			flights, err := p.Search(context.Background(), testSearchRequest())
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(flights) == 0 {
+				t.Fatalf("expected flights from %s", tt.fixture)
+			}
+			for _, f := range flights {
+				if f.Provider != tt.name {
+					t.Fatalf("expected provider %q, got %q", tt.name, f.Provider)
+				}
+				if f.Price.Amount <= 0 || f.Price.Currency == "" {
+					t.Fatalf("flight %s: invalid price %+v", f.ID, f.Price)
+				}
+				if len(f.Price.Fares) != 2 {
+					t.Fatalf("flight %s: expected adult and child fares, got %+v", f.ID, f.Price.Fares)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistryBuildErrors(t *testing.T) {
+	registry := NewRegistry()
+	tests := []struct {
+		name string
+		cfg  Config
+		want error
+	}{
+		{name: "unknown type", cfg: Config{Type: "unknown", Fixture: "x.json"}, want: ErrUnknownType},
+		{name: "no source", cfg: Config{Type: TypeLionAir}, want: ErrInvalidConfig},
+		{name: "both sources", cfg: Config{Type: TypeLionAir, Fixture: "x.json", BaseURL: "http://localhost"}, want: ErrInvalidConfig},
+		{name: "invalid base url", cfg: Config{Type: TypeLionAir, BaseURL: "localhost"}, want: ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := registry.Build(tt.cfg, Options{}); !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFileTransportReadsFixture(t *testing.T) {
+	transport := NewFileTransport(filepath.Join("..", "..", "..", "mocks", "lion_air_search_response.json"), Simulation{})
+	data, err := transport.Fetch(context.Background(), testSearchRequest())
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected fixture content")
+	}
+
+	failing := NewFileTransport("missing.json", Simulation{FailureRate: 1})
+	if _, err := failing.Fetch(context.Background(), testSearchRequest()); !errors.Is(err, ErrTemporary) {
+		t.Fatalf("expected ErrTemporary, got %v", err)
+	}
+}
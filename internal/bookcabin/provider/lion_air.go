@@ -4,41 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
 type LionAirProvider struct {
-	path string
-	rng  *SafeRand
+	transport Transport
+	opts      Options
 }
 
-func NewLionAirProvider(path string) *LionAirProvider {
-	return &LionAirProvider{path: path, rng: NewSafeRand()}
+func NewLionAirProvider(transport Transport, opts Options) *LionAirProvider {
+	return &LionAirProvider{transport: transport, opts: opts}
 }
 
 func (l *LionAirProvider) Name() string {
 	return "Lion Air"
 }
 
-func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity.Flight, error) {
-	delay := time.Duration(100+l.rng.Intn(101)) * time.Millisecond
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case <-time.After(delay):
-	}
-
-	path := filepath.Clean(l.path)
-	data, err := os.ReadFile(path)
+func (l *LionAirProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
+	data, err := l.transport.Fetch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("lion air read file: %w", err)
+		return nil, fmt.Errorf("lion air fetch: %w", err)
 	}
+	return l.decode(data, req)
+}
 
+func (l *LionAirProvider) decode(data []byte, req SearchRequest) ([]entity.Flight, error) {
 	var resp struct {
 		Success bool `json:"success"`
 		Data    struct {
@@ -48,6 +40,10 @@ func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 					Name string `json:"name"`
 					IATA string `json:"iata"`
 				} `json:"carrier"`
+				OperatedBy struct {
+					IATA         string `json:"iata"`
+					FlightNumber string `json:"flight_number"`
+				} `json:"operated_by"`
 				Route struct {
 					From struct {
 						Code string `json:"code"`
@@ -67,7 +63,11 @@ func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 				FlightTime int  `json:"flight_time"`
 				IsDirect   bool `json:"is_direct"`
 				StopCount  int  `json:"stop_count"`
-				Pricing    struct {
+				Layovers   []struct {
+					Airport         string `json:"airport"`
+					DurationMinutes int    `json:"duration_minutes"`
+				} `json:"layovers"`
+				Pricing struct {
 					Total    int    `json:"total"`
 					Currency string `json:"currency"`
 					FareType string `json:"fare_type"`
@@ -87,16 +87,16 @@ func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 	}
 
 	if err := json.Unmarshal(data, &resp); err != nil {
-		return nil, fmt.Errorf("lion air decode: %w", err)
+		return nil, fmt.Errorf("lion air: %w: %w", ErrDecode, err)
 	}
 
 	flights := make([]entity.Flight, 0, len(resp.Data.AvailableFlights))
 	for _, f := range resp.Data.AvailableFlights {
-		departAt, err := parseTimeInLocation(f.Schedule.Departure, "2006-01-02T15:04:05", f.Schedule.DepartureTimezone)
+		departAt, err := l.opts.parseLocalTime(f.Schedule.Departure, f.Schedule.DepartureTimezone, f.Route.From.Code)
 		if err != nil {
 			return nil, fmt.Errorf("lion air departure time: %w", err)
 		}
-		arriveAt, err := parseTimeInLocation(f.Schedule.Arrival, "2006-01-02T15:04:05", f.Schedule.ArrivalTimezone)
+		arriveAt, err := l.opts.parseLocalTime(f.Schedule.Arrival, f.Schedule.ArrivalTimezone, f.Route.To.Code)
 		if err != nil {
 			return nil, fmt.Errorf("lion air arrival time: %w", err)
 		}
@@ -109,10 +109,10 @@ func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 
 		amenities := make([]string, 0, 2)
 		if f.Services.WifiAvailable {
-			amenities = append(amenities, "wifi")
+			amenities = append(amenities, entity.AmenityWifi)
 		}
 		if f.Services.MealsIncluded {
-			amenities = append(amenities, "meal")
+			amenities = append(amenities, entity.AmenityMeal)
 		}
 
 		stops := 0
@@ -123,24 +123,42 @@ func (l *LionAirProvider) Search(ctx context.Context, _ SearchRequest) ([]entity
 			}
 		}
 
+		stopovers := make([]stopover, 0, len(f.Layovers))
+		for _, layover := range f.Layovers {
+			stopovers = append(stopovers, stopover{Airport: layover.Airport, LayoverMinute: layover.DurationMinutes})
+		}
+
+		departure := entity.FlightPoint{Airport: f.Route.From.Code, City: f.Route.From.City, Time: departAt}
+		arrival := entity.FlightPoint{Airport: f.Route.To.Code, City: f.Route.To.City, Time: arriveAt}
+
+		marketing := l.opts.airline(f.Carrier.IATA, f.Carrier.Name)
+		operating, operatingNumber := l.opts.operatingCarrier(marketing, f.ID, f.OperatedBy.IATA, f.OperatedBy.FlightNumber)
+
 		duration := durationMinutes(departAt, arriveAt, f.FlightTime)
 		flights = append(flights, entity.Flight{
-			ID:             fmt.Sprintf("%s_%s", f.ID, l.Name()),
-			Provider:       l.Name(),
-			Airline:        entity.Airline{Name: f.Carrier.Name, Code: f.Carrier.IATA},
-			FlightNumber:   f.ID,
-			Departure:      entity.FlightPoint{Airport: f.Route.From.Code, City: f.Route.From.City, Time: departAt},
-			Arrival:        entity.FlightPoint{Airport: f.Route.To.Code, City: f.Route.To.City, Time: arriveAt},
-			DurationMinute: duration,
-			Stops:          stops,
-			Price:          entity.Price{Amount: f.Pricing.Total, Currency: f.Pricing.Currency},
+			ID:                    fmt.Sprintf("%s_%s", f.ID, l.Name()),
+			Provider:              l.Name(),
+			Airline:               marketing,
+			FlightNumber:          f.ID,
+			OperatingAirline:      operating,
+			OperatingFlightNumber: operatingNumber,
+			Departure:             departure,
+			Arrival:               arrival,
+			DurationMinute:        duration,
+			Stops:                 stops,
+			Segments:              buildSegments(f.ID, departure, arrival, stopovers, l.opts),
+			Price: entity.Price{
+				Amount:   f.Pricing.Total,
+				Currency: f.Pricing.Currency,
+				Fares:    passengerFares(f.Pricing.Total, req.Passengers, l.opts.FareRatio),
+			},
 			AvailableSeats: f.SeatsLeft,
 			CabinClass:     strings.ToLower(f.Pricing.FareType),
 			Aircraft:       aircraftPtr,
 			Amenities:      amenities,
 			Baggage: entity.Baggage{
-				CarryOn: f.Services.Baggage.Cabin,
-				Checked: f.Services.Baggage.Hold,
+				CarryOn: baggageAllowance(f.Services.Baggage.Cabin),
+				Checked: baggageAllowance(f.Services.Baggage.Hold),
 			},
 		})
 	}
@@ -5,20 +5,37 @@ import (
 	"errors"
 	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
-var ErrTemporary = errors.New("temporary provider error")
+var (
+	ErrTemporary   = errors.New("temporary provider error")
+	ErrDecode      = errors.New("decode provider response")
+	ErrRateLimited = errors.New("provider rate limited")
+)
 
 type SearchRequest struct {
 	Origin        string
 	Destination   string
 	DepartureDate time.Time
 	ReturnDate    *time.Time
-	Passengers    int
+	Passengers    entity.Passengers
 	CabinClass    string
 }
 
+type FareRatio struct {
+	Child  float64
+	Infant float64
+}
+
+type Options struct {
+	FareRatio FareRatio
+	Airports  airport.Directory
+	Airlines  airline.Directory
+}
+
 type Provider interface {
 	Name() string
 	Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error)
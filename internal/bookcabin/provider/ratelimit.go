@@ -2,12 +2,18 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 )
 
+// errLocalRateLimit marks calls that never left our own rate limiter, as
+// opposed to a provider answering with a rate limit.
+var errLocalRateLimit = errors.New("waiting for rate limit slot")
+
 type rateLimiter struct {
 	mu       sync.Mutex
 	interval time.Duration
@@ -58,7 +64,7 @@ func (r *rateLimitedProvider) Name() string {
 
 func (r *rateLimitedProvider) Search(ctx context.Context, req SearchRequest) ([]entity.Flight, error) {
 	if err := r.limiter.Wait(ctx); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w: %w", ErrRateLimited, errLocalRateLimit, err)
 	}
 	return r.provider.Search(ctx, req)
 }
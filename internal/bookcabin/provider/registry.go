@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	TypeGarudaIndonesia = "garuda_indonesia"
+	TypeLionAir         = "lion_air"
+	TypeBatikAir        = "batik_air"
+	TypeAirAsia         = "airasia"
+)
+
+var (
+	ErrUnknownType   = errors.New("unknown provider type")
+	ErrInvalidConfig = errors.New("invalid provider config")
+)
+
+type Config struct {
+	Type    string
+	Fixture string
+	BaseURL string
+	Headers map[string]string
+	Auth    HTTPAuth
+	Timeout time.Duration
+}
+
+type Factory func(cfg Config, opts Options) (Provider, error)
+
+type Registry struct {
+	factories map[string]Factory
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{factories: map[string]Factory{}}
+	r.Register(TypeGarudaIndonesia, adapterFactory(
+		Simulation{MinDelay: 50 * time.Millisecond, MaxDelay: 100 * time.Millisecond},
+		func(t Transport, opts Options) Provider { return NewGarudaIndonesiaProvider(t, opts) },
+	))
+	r.Register(TypeLionAir, adapterFactory(
+		Simulation{MinDelay: 100 * time.Millisecond, MaxDelay: 200 * time.Millisecond},
+		func(t Transport, opts Options) Provider { return NewLionAirProvider(t, opts) },
+	))
+	r.Register(TypeBatikAir, adapterFactory(
+		Simulation{MinDelay: 200 * time.Millisecond, MaxDelay: 400 * time.Millisecond},
+		func(t Transport, opts Options) Provider { return NewBatikAirProvider(t, opts) },
+	))
+	r.Register(TypeAirAsia, adapterFactory(
+		Simulation{MinDelay: 50 * time.Millisecond, MaxDelay: 150 * time.Millisecond, FailureRate: 0.1},
+		func(t Transport, opts Options) Provider { return NewAirAsiaProvider(t, opts) },
+	))
+	return r
+}
+
+func (r *Registry) Register(typeName string, factory Factory) {
+	r.factories[strings.ToLower(typeName)] = factory
+}
+
+func (r *Registry) Build(cfg Config, opts Options) (Provider, error) {
+	factory, ok := r.factories[strings.ToLower(cfg.Type)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownType, cfg.Type)
+	}
+	return factory(cfg, opts)
+}
+
+func (c Config) Transport(sim Simulation) (Transport, error) {
+	switch {
+	case c.BaseURL != "" && c.Fixture != "":
+		return nil, fmt.Errorf("%w: set either fixture or base_url, not both", ErrInvalidConfig)
+	case c.BaseURL != "":
+		return NewHTTPTransport(HTTPTransportOptions{
+			BaseURL: c.BaseURL,
+			Headers: c.Headers,
+			Auth:    c.Auth,
+			Timeout: c.Timeout,
+		})
+	case c.Fixture != "":
+		return NewFileTransport(c.Fixture, sim), nil
+	default:
+		return nil, fmt.Errorf("%w: fixture or base_url is required", ErrInvalidConfig)
+	}
+}
+
+func adapterFactory(sim Simulation, build func(t Transport, opts Options) Provider) Factory {
+	return func(cfg Config, opts Options) (Provider, error) {
+		transport, err := cfg.Transport(sim)
+		if err != nil {
+			return nil, err
+		}
+		return build(transport, opts), nil
+	}
+}
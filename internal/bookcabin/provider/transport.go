@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Transport interface {
+	Fetch(ctx context.Context, req SearchRequest) ([]byte, error)
+}
+
+type Simulation struct {
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+	FailureRate float64
+}
+
+type FileTransport struct {
+	path string
+	sim  Simulation
+	rng  *SafeRand
+}
+
+func NewFileTransport(path string, sim Simulation) *FileTransport {
+	return &FileTransport{path: path, sim: sim, rng: NewSafeRand()}
+}
+
+func (f *FileTransport) Fetch(ctx context.Context, _ SearchRequest) ([]byte, error) {
+	delay := f.sim.MinDelay
+	if spread := f.sim.MaxDelay - f.sim.MinDelay; spread > 0 {
+		delay += time.Duration(f.rng.Intn(int(spread/time.Millisecond)+1)) * time.Millisecond
+	}
+	if delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	if f.sim.FailureRate > 0 && f.rng.Float64() < f.sim.FailureRate {
+		return nil, ErrTemporary
+	}
+
+	data, err := os.ReadFile(filepath.Clean(f.path))
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	return data, nil
+}
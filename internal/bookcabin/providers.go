@@ -0,0 +1,181 @@
+package bookcabin
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/usecase"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgconfig"
+)
+
+const providersKey = "modules.book-cabin.providers"
+
+type providerSettings struct {
+	key       string
+	enabled   bool
+	config    provider.Config
+	timeout   time.Duration
+	retries   int
+	rateLimit time.Duration
+	breaker   provider.CircuitBreakerOptions
+}
+
+type providerDefaults struct {
+	timeout   time.Duration
+	retries   int
+	rateLimit time.Duration
+	breaker   provider.CircuitBreakerOptions
+}
+
+func buildProviders(
+	cfg pkgconfig.Config,
+	registry *provider.Registry,
+	opts provider.Options,
+	defaults providerDefaults,
+) ([]provider.Provider, map[string]usecase.ProviderOption, error) {
+	settings, err := loadProviderSettings(cfg, defaults)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	providers := make([]provider.Provider, 0, len(settings))
+	options := make(map[string]usecase.ProviderOption, len(settings))
+	for _, s := range settings {
+		if !s.enabled {
+			continue
+		}
+
+		p, err := registry.Build(s.config, opts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("provider %q: %w", s.key, err)
+		}
+		if _, exists := options[p.Name()]; exists {
+			return nil, nil, fmt.Errorf("provider %q: %w: %s is already configured", s.key, provider.ErrInvalidConfig, p.Name())
+		}
+		if s.rateLimit > 0 {
+			p = provider.NewRateLimitedProvider(p, s.rateLimit)
+		}
+		if s.breaker.FailureThreshold > 0 {
+			p = provider.NewCircuitBreakerProvider(p, s.breaker)
+		}
+
+		providers = append(providers, p)
+		options[p.Name()] = usecase.ProviderOption{Timeout: s.timeout, Retries: s.retries}
+	}
+
+	if len(providers) == 0 {
+		return nil, nil, fmt.Errorf("%w: no providers enabled", provider.ErrInvalidConfig)
+	}
+
+	return providers, options, nil
+}
+
+func loadProviderSettings(cfg pkgconfig.Config, defaults providerDefaults) ([]providerSettings, error) {
+	keys := cfg.GetKeys(providersKey)
+	if len(keys) == 0 {
+		return defaultProviderSettings(defaults), nil
+	}
+
+	settings := make([]providerSettings, 0, len(keys))
+	for _, key := range keys {
+		prefix := providersKey + "." + key
+		fields := cfg.GetKeys(prefix)
+		has := func(field string) bool { return slices.Contains(fields, field) }
+
+		s := providerSettings{
+			key:     key,
+			enabled: !has("enabled") || cfg.GetBool(prefix+".enabled"),
+			config: provider.Config{
+				Type:    cfg.GetString(prefix + ".type"),
+				Fixture: cfg.GetString(prefix + ".fixture"),
+				BaseURL: cfg.GetString(prefix + ".base_url"),
+				Headers: loadHeaders(cfg, prefix+".headers"),
+				Auth: provider.HTTPAuth{
+					Scheme: cfg.GetString(prefix + ".auth_scheme"),
+					Token:  cfg.GetString(prefix + ".auth_token"),
+				},
+			},
+			timeout:   defaults.timeout,
+			retries:   defaults.retries,
+			rateLimit: defaults.rateLimit,
+			breaker:   defaults.breaker,
+		}
+		if s.config.Type == "" {
+			s.config.Type = key
+		}
+		if env := cfg.GetString(prefix + ".auth_token_env"); env != "" {
+			s.config.Auth.Token = os.Getenv(env)
+		}
+		if has("timeout_ms") {
+			s.timeout = time.Duration(cfg.GetInt(prefix+".timeout_ms")) * time.Millisecond
+		}
+		if has("retries") {
+			s.retries = int(cfg.GetInt(prefix + ".retries"))
+		}
+		if has("rate_limit_ms") {
+			s.rateLimit = time.Duration(cfg.GetInt(prefix+".rate_limit_ms")) * time.Millisecond
+		}
+		s.breaker = loadBreakerOptions(cfg, prefix+".circuit_breaker", s.breaker)
+
+		if s.timeout <= 0 || s.retries < 0 || s.rateLimit < 0 || s.breaker.FailureThreshold < 0 || s.breaker.CoolDown < 0 {
+			return nil, fmt.Errorf("provider %q: %w: timeout_ms must be positive, other limits must not be negative", key, provider.ErrInvalidConfig)
+		}
+		s.config.Timeout = s.timeout
+		settings = append(settings, s)
+	}
+
+	return settings, nil
+}
+
+func loadHeaders(cfg pkgconfig.Config, key string) map[string]string {
+	names := cfg.GetKeys(key)
+	if len(names) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(names))
+	for _, name := range names {
+		headers[name] = cfg.GetString(key + "." + name)
+	}
+	return headers
+}
+
+func defaultProviderSettings(defaults providerDefaults) []providerSettings {
+	fixtures := []struct {
+		typeName string
+		fixture  string
+	}{
+		{provider.TypeGarudaIndonesia, "mocks/garuda_indonesia_search_response.json"},
+		{provider.TypeLionAir, "mocks/lion_air_search_response.json"},
+		{provider.TypeBatikAir, "mocks/batik_air_search_response.json"},
+		{provider.TypeAirAsia, "mocks/airasia_search_response.json"},
+	}
+
+	settings := make([]providerSettings, 0, len(fixtures))
+	for _, f := range fixtures {
+		settings = append(settings, providerSettings{
+			key:       f.typeName,
+			enabled:   true,
+			config:    provider.Config{Type: f.typeName, Fixture: f.fixture, Timeout: defaults.timeout},
+			timeout:   defaults.timeout,
+			retries:   defaults.retries,
+			rateLimit: defaults.rateLimit,
+			breaker:   defaults.breaker,
+		})
+	}
+	return settings
+}
+
+func loadBreakerOptions(cfg pkgconfig.Config, key string, fallback provider.CircuitBreakerOptions) provider.CircuitBreakerOptions {
+	fields := cfg.GetKeys(key)
+	opts := fallback
+	if slices.Contains(fields, "failure_threshold") {
+		opts.FailureThreshold = int(cfg.GetInt(key + ".failure_threshold"))
+	}
+	if slices.Contains(fields, "cool_down_ms") {
+		opts.CoolDown = time.Duration(cfg.GetInt(key+".cool_down_ms")) * time.Millisecond
+	}
+	return opts
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func (u *Usecase) Airlines(_ context.Context) []airline.Airline {
+	if u.airlines == nil {
+		return []airline.Airline{}
+	}
+	return u.airlines.Airlines()
+}
+
+// resolveAirlineFilter maps the airline filter onto IATA codes so "garuda",
+// "GIA" and "GA" select the same flights. Values the registry does not know
+// are kept and still match the name or code a provider sent.
+func (u *Usecase) resolveAirlineFilter(values []string) []string {
+	if len(values) == 0 || u.airlines == nil {
+		return values
+	}
+	resolved := make([]string, 0, len(values))
+	for _, value := range values {
+		if a, ok := u.airlines.Airline(value); ok {
+			value = a.Code
+		}
+		resolved = append(resolved, value)
+	}
+	return resolved
+}
+
+func matchAirlineFilter(f entity.Flight, filters FlightFilters, airlineFilter map[string]struct{}) bool {
+	if len(airlineFilter) > 0 {
+		_, byName := airlineFilter[strings.ToLower(f.Airline.Name)]
+		_, byCode := airlineFilter[strings.ToLower(f.Airline.Code)]
+		if !byName && !byCode {
+			return false
+		}
+	}
+	if len(filters.AirlineTypes) > 0 && !containsFold(filters.AirlineTypes, f.Airline.Type) {
+		return false
+	}
+	if len(filters.Alliances) > 0 && !containsFold(filters.Alliances, f.Airline.Alliance) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, target string) bool {
+	if target == "" {
+		return false
+	}
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func TestResolveAirlineFilter(t *testing.T) {
+	registry, err := airline.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	dep := testDependency()
+	dep.Airlines = registry
+	u := newUsecaseFrom(t, dep)
+
+	got := u.resolveAirlineFilter([]string{"garuda", "GIA", "Lion Air", "Unknown Air"})
+	if want := []string{"GA", "GA", "JT", "Unknown Air"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	bare := newTestUsecase(t)
+	if got := bare.resolveAirlineFilter([]string{"garuda"}); !reflect.DeepEqual(got, []string{"garuda"}) {
+		t.Fatalf("expected values to pass through without a directory, got %v", got)
+	}
+}
+
+func TestFlightsAirlineFilters(t *testing.T) {
+	garuda := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)
+	garuda.Airline = entity.Airline{Code: "GA", Name: "Garuda Indonesia", Type: airline.TypeFullService, Alliance: airline.AllianceSkyTeam}
+	batik := stubFlight("ID6514", "CGK", "DPS", at(15, 7), 1100000)
+	batik.Airline = entity.Airline{Code: "ID", Name: "Batik Air", Type: airline.TypeFullService}
+	lion := stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000)
+	lion.Airline = entity.Airline{Code: "JT", Name: "Lion Air", Type: airline.TypeLCC}
+	unknown := stubFlight("XX100", "CGK", "DPS", at(15, 21), 500000)
+
+	registry, err := airline.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	dep := testDependency(&stubProvider{flights: []entity.Flight{garuda, batik, lion, unknown}})
+	dep.Airlines = registry
+	u := newUsecaseFrom(t, dep)
+
+	tests := []struct {
+		name    string
+		filters FlightFilters
+		wantIDs []string
+	}{
+		{name: "no filter", wantIDs: []string{"GA400", "ID6514", "JT740", "XX100"}},
+		{name: "airline alias", filters: FlightFilters{Airlines: []string{"garuda"}}, wantIDs: []string{"GA400"}},
+		{name: "airline icao", filters: FlightFilters{Airlines: []string{"LNI"}}, wantIDs: []string{"JT740"}},
+		{name: "full service", filters: FlightFilters{AirlineTypes: []string{"full_service"}}, wantIDs: []string{"GA400", "ID6514"}},
+		{name: "lcc", filters: FlightFilters{AirlineTypes: []string{"LCC"}}, wantIDs: []string{"JT740"}},
+		{name: "alliance", filters: FlightFilters{Alliances: []string{"skyteam", "oneworld"}}, wantIDs: []string{"GA400"}},
+		{name: "type and alliance", filters: FlightFilters{AirlineTypes: []string{"lcc"}, Alliances: []string{"skyteam"}}, wantIDs: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := oneWayInput
+			in.Filters = tt.filters
+			in.Sort = []SortOption{{Field: "departure"}}
+			out, err := u.Flights(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Flights: %v", err)
+			}
+			ids := make([]string, 0, len(out.Flights))
+			for _, f := range out.Flights {
+				ids = append(ids, f.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+// maxAirportPairs caps the provider fan-out of a single leg; every pair is a
+// full round of provider requests.
+const maxAirportPairs = 9
+
+type airportPair struct {
+	origin      string
+	destination string
+}
+
+// resolveAirports expands a comma-separated list of airport or city codes,
+// plus the airports within radiusKm of each, into distinct airport codes.
+// Codes missing from the directory are kept as they are, so providers still
+// get a chance to serve airports the dataset does not know about.
+func (u *Usecase) resolveAirports(codes string, radiusKm float64) []string {
+	airports := make([]string, 0)
+	add := func(code string) {
+		if !slices.Contains(airports, code) {
+			airports = append(airports, code)
+		}
+	}
+
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		resolved := []string{code}
+		if u.airports != nil {
+			if known := u.airports.Resolve(code); len(known) > 0 {
+				resolved = known
+			}
+		}
+		for _, a := range resolved {
+			add(a)
+		}
+	}
+
+	if radiusKm > 0 && u.airports != nil {
+		for _, a := range slices.Clone(airports) {
+			for _, nearby := range u.airports.Nearby(a, radiusKm) {
+				add(nearby)
+			}
+		}
+	}
+
+	return airports
+}
+
+func (u *Usecase) airportPairs(in FlightsInput) ([]string, []string, []airportPair, error) {
+	origins := u.resolveAirports(in.Origin, in.OriginRadiusKm)
+	destinations := u.resolveAirports(in.Destination, in.DestinationRadiusKm)
+
+	pairs := make([]airportPair, 0, len(origins)*len(destinations))
+	for _, origin := range origins {
+		for _, destination := range destinations {
+			if origin != destination {
+				pairs = append(pairs, airportPair{origin: origin, destination: destination})
+			}
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, nil, nil, pkgerror.NewBusiness("origin and destination must differ", pkgerror.CodeInvalidInput)
+	}
+	if len(pairs) > maxAirportPairs {
+		msg := fmt.Sprintf("origin and destination cover %d airport pairs, at most %d are allowed", len(pairs), maxAirportPairs)
+		return nil, nil, nil, pkgerror.NewBusiness(msg, pkgerror.CodeInvalidInput)
+	}
+
+	return origins, destinations, pairs, nil
+}
+
+func pairRequests(pairs []airportPair, base provider.SearchRequest, reverse bool) []provider.SearchRequest {
+	reqs := make([]provider.SearchRequest, 0, len(pairs))
+	for _, pair := range pairs {
+		req := base
+		req.Origin, req.Destination = pair.origin, pair.destination
+		if reverse {
+			req.Origin, req.Destination = pair.destination, pair.origin
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func newAirportUsecase(t *testing.T, providers ...*stubProvider) *Usecase {
+	t.Helper()
+	registry, err := airport.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	dep := testDependency()
+	for _, p := range providers {
+		dep.Providers = append(dep.Providers, p)
+	}
+	dep.Airports = registry
+	return newUsecaseFrom(t, dep)
+}
+
+func TestResolveAirports(t *testing.T) {
+	u := newAirportUsecase(t)
+	tests := []struct {
+		name     string
+		codes    string
+		radiusKm float64
+		want     []string
+	}{
+		{name: "airport", codes: "cgk", want: []string{"CGK"}},
+		{name: "city code", codes: "JKT", want: []string{"CGK", "HLP"}},
+		{name: "list without duplicates", codes: "CGK, hlp ,JKT,", want: []string{"CGK", "HLP"}},
+		{name: "unknown codes are kept", codes: "JKT,XYZ", want: []string{"CGK", "HLP", "XYZ"}},
+		{name: "radius adds the closest first", codes: "DPS", radiusKm: 150, want: []string{"DPS", "BWX", "LOP"}},
+		{name: "radius around every airport", codes: "JKT", radiusKm: 30, want: []string{"CGK", "HLP"}},
+		{name: "radius too small", codes: "DPS", radiusKm: 50, want: []string{"DPS"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.resolveAirports(tt.codes, tt.radiusKm); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	bare := newTestUsecase(t)
+	if got := bare.resolveAirports("JKT", 100); !reflect.DeepEqual(got, []string{"JKT"}) {
+		t.Fatalf("expected codes to pass through without a directory, got %v", got)
+	}
+}
+
+func TestAirportPairs(t *testing.T) {
+	u := newAirportUsecase(t)
+	tests := []struct {
+		name        string
+		origin      string
+		destination string
+		wantPairs   int
+		wantErr     string
+	}{
+		{name: "single pair", origin: "CGK", destination: "DPS", wantPairs: 1},
+		{name: "city code", origin: "JKT", destination: "DPS", wantPairs: 2},
+		{name: "same airport is skipped", origin: "JKT", destination: "CGK", wantPairs: 1},
+		{name: "at the cap", origin: "JKT,BDO", destination: "DPS,LOP,SUB", wantPairs: 9},
+		{name: "over the cap", origin: "JKT,BDO,SRG", destination: "DPS,LOP,SUB", wantErr: "origin and destination cover 12 airport pairs, at most 9 are allowed"},
+		{name: "same airport", origin: "CGK", destination: "cgk", wantErr: "origin and destination must differ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, pairs, err := u.airportPairs(FlightsInput{Origin: tt.origin, Destination: tt.destination})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("airportPairs: %v", err)
+			}
+			if len(pairs) != tt.wantPairs {
+				t.Fatalf("expected %d pairs, got %+v", tt.wantPairs, pairs)
+			}
+			for _, pair := range pairs {
+				if pair.origin == pair.destination {
+					t.Fatalf("expected no same-airport pair, got %+v", pair)
+				}
+			}
+		})
+	}
+}
+
+func TestFlightsMergesCityCodeAirports(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("ID6500", "HLP", "DPS", at(15, 8), 900000),
+		stubFlight("JT740", "BDO", "DPS", at(15, 9), 700000),
+	}}
+	u := newAirportUsecase(t, stub)
+	in := oneWayInput
+	in.Origin = "JKT"
+
+	out, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if got := out.SearchCriteria.OriginAirports; !reflect.DeepEqual(got, []string{"CGK", "HLP"}) {
+		t.Fatalf("expected JKT to resolve to CGK and HLP, got %v", got)
+	}
+	if calls := stub.calls.Load(); calls != 2 {
+		t.Fatalf("expected one provider call per airport pair, got %d", calls)
+	}
+	if len(out.Flights) != 2 {
+		t.Fatalf("expected the flights of both Jakarta airports, got %d", len(out.Flights))
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+func (u *Usecase) resolveCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return u.defaultCurrency
+	}
+	return code
+}
+
+func (u *Usecase) validateCurrency(ctx context.Context, code string) error {
+	if strings.EqualFold(code, u.defaultCurrency) {
+		return nil
+	}
+	if _, err := u.rate(ctx, u.defaultCurrency, code); err != nil {
+		if errors.Is(err, currency.ErrUnsupported) {
+			return pkgerror.NewBusiness("unsupported currency", pkgerror.CodeInvalidInput)
+		}
+		return pkgerror.NewServer(err)
+	}
+	return nil
+}
+
+func (u *Usecase) rate(ctx context.Context, from, to string) (float64, error) {
+	if u.rates == nil {
+		return 0, currency.ErrUnsupported
+	}
+	return u.rates.Rate(ctx, from, to)
+}
+
+func (u *Usecase) convertPrices(ctx context.Context, flights []entity.Flight, target string) []entity.Flight {
+	converted := make([]entity.Flight, 0, len(flights))
+	rates := map[string]float64{}
+	for _, flight := range flights {
+		from := strings.ToUpper(flight.Price.Currency)
+		if from == "" {
+			from = u.defaultCurrency
+		}
+		if from == target {
+			flight.Price.Currency = target
+			converted = append(converted, flight)
+			continue
+		}
+
+		rate, ok := rates[from]
+		if !ok {
+			var err error
+			rate, err = u.rate(ctx, from, target)
+			if err != nil {
+				slog.WarnContext(ctx, "skip flight with unconvertible price", "flight_id", flight.ID, "currency", from, "error", err)
+				continue
+			}
+			rates[from] = rate
+		}
+
+		flight.Price = convertPrice(flight.Price, from, target, rate)
+		converted = append(converted, flight)
+	}
+	return converted
+}
+
+func convertPrice(price entity.Price, from, to string, rate float64) entity.Price {
+	convert := func(amount int) int {
+		return currency.Convert(amount, from, to, rate)
+	}
+
+	converted := entity.Price{
+		Amount:     convert(price.Amount),
+		Total:      convert(price.Total),
+		Passengers: price.Passengers,
+		Currency:   to,
+	}
+	if price.Breakdown != nil {
+		converted.Breakdown = &entity.PriceBreakdown{
+			BaseFare: convert(price.Breakdown.BaseFare),
+			Taxes:    convert(price.Breakdown.Taxes),
+			Fees:     convert(price.Breakdown.Fees),
+		}
+	}
+	if len(price.Fares) > 0 {
+		converted.Fares = make([]entity.PassengerFare, 0, len(price.Fares))
+		for _, fare := range price.Fares {
+			amount := convert(fare.Amount)
+			converted.Fares = append(converted.Fares, entity.PassengerFare{
+				Type:   fare.Type,
+				Count:  fare.Count,
+				Amount: amount,
+				Total:  amount * fare.Count,
+			})
+		}
+	}
+	return converted
+}
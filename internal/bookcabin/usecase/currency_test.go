@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+func TestConvertPrices(t *testing.T) {
+	u := newTestUsecase(t)
+	rupiah := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)
+	rupiah.Price.Total = 2500000
+	rupiah.Price.Breakdown = &entity.PriceBreakdown{BaseFare: 1100000, Taxes: 100000, Fees: 50000}
+	rupiah.Price.Fares = []entity.PassengerFare{{Type: entity.PassengerAdult, Count: 2, Amount: 1250000, Total: 2500000}}
+	dollars := stubFlight("SQ940", "CGK", "DPS", at(15, 8), 8000)
+	dollars.Price.Currency = "USD"
+	pounds := stubFlight("BA011", "CGK", "DPS", at(15, 9), 6000)
+	pounds.Price.Currency = "GBP"
+	unpriced := stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000)
+	unpriced.Price.Currency = ""
+
+	got := u.convertPrices(context.Background(), []entity.Flight{rupiah, dollars, pounds, unpriced}, "USD")
+
+	ids := make([]string, 0, len(got))
+	for _, f := range got {
+		ids = append(ids, f.ID)
+		if f.Price.Currency != "USD" {
+			t.Fatalf("%s: expected USD, got %s", f.ID, f.Price.Currency)
+		}
+	}
+	if want := []string{"GA400", "SQ940", "JT740"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected the GBP flight to be skipped, got %v", ids)
+	}
+
+	want := entity.Price{
+		Amount:     7813,
+		Total:      15625,
+		Currency:   "USD",
+		Breakdown:  &entity.PriceBreakdown{BaseFare: 6875, Taxes: 625, Fees: 313},
+		Fares:      []entity.PassengerFare{{Type: entity.PassengerAdult, Count: 2, Amount: 7813, Total: 15626}},
+		Passengers: 0,
+	}
+	if !reflect.DeepEqual(got[0].Price, want) {
+		t.Fatalf("expected %+v, got %+v", want, got[0].Price)
+	}
+	if got[1].Price.Amount != 8000 {
+		t.Fatalf("expected the USD price to stay 8000, got %d", got[1].Price.Amount)
+	}
+	if got[2].Price.Amount != 4875 {
+		t.Fatalf("expected a missing currency to be read as IDR, got %+v", got[2].Price)
+	}
+}
+
+func TestFlightsCurrency(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)}}
+	u := newTestUsecase(t, stub)
+	in := FlightsInput{Origin: "CGK", Destination: "DPS", DepartureDate: at(15, 0), Passengers: entity.Passengers{Adults: 1}}
+
+	out, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if out.SearchCriteria.Currency != "IDR" || out.Flights[0].Price.Amount != 1250000 {
+		t.Fatalf("expected the default currency, got %s and %+v", out.SearchCriteria.Currency, out.Flights[0].Price)
+	}
+
+	in.Currency = "sgd"
+	out, err = u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if out.SearchCriteria.Currency != "SGD" || out.Flights[0].Price.Amount != 10413 {
+		t.Fatalf("expected 104.13 SGD, got %s and %+v", out.SearchCriteria.Currency, out.Flights[0].Price)
+	}
+
+	in.Currency = "GBP"
+	_, err = u.Flights(context.Background(), in)
+	var gerr *pkgerror.Error
+	if !errors.As(err, &gerr) || gerr.Code() != pkgerror.CodeInvalidInput {
+		t.Fatalf("expected an invalid input error for GBP, got %v", err)
+	}
+}
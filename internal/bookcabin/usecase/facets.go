@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+// priceHistogramBuckets is how many equal-width price ranges the histogram
+// splits a leg into.
+const priceHistogramBuckets = 5
+
+// Facets summarize the flights of one leg before the optional filters apply,
+// so a filter sidebar can show what each filter would leave. Prices are per
+// passenger in the search currency.
+type Facets struct {
+	Total          int
+	Currency       string
+	Airlines       []FacetBucket
+	Stops          []FacetBucket
+	DepartureTimes []FacetBucket
+	PriceRange     *PriceRange
+	PriceHistogram []PriceBucket
+	Duration       *DurationRange
+}
+
+type FacetBucket struct {
+	Value    string
+	Label    string
+	Count    int
+	MinPrice int
+}
+
+type PriceBucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+type DurationRange struct {
+	Min int
+	Max int
+}
+
+func buildFacets(flights []entity.Flight, currencyCode string) Facets {
+	facets := Facets{
+		Total:          len(flights),
+		Currency:       currencyCode,
+		Airlines:       []FacetBucket{},
+		Stops:          []FacetBucket{},
+		DepartureTimes: []FacetBucket{},
+		PriceHistogram: []PriceBucket{},
+	}
+	if len(flights) == 0 {
+		return facets
+	}
+
+	airlines := newFacetCounter()
+	stops := newFacetCounter()
+	times := newFacetCounter()
+	duration := DurationRange{Min: flights[0].DurationMinute, Max: flights[0].DurationMinute}
+	for _, f := range flights {
+		airlines.add(f.Airline.Code, f.Airline.Name, f.Price.Amount)
+		stops.add(strconv.Itoa(f.Stops), "", f.Price.Amount)
+		times.add(timeOfDayOf(f.Departure.Time), "", f.Price.Amount)
+		duration.Min = min(duration.Min, f.DurationMinute)
+		duration.Max = max(duration.Max, f.DurationMinute)
+	}
+
+	facets.Airlines = airlines.buckets(func(a, b FacetBucket) int { return cmp.Compare(a.Label, b.Label) })
+	facets.Stops = stops.buckets(func(a, b FacetBucket) int {
+		ai, _ := strconv.Atoi(a.Value)
+		bi, _ := strconv.Atoi(b.Value)
+		return cmp.Compare(ai, bi)
+	})
+	order := []string{TimeOfDayMorning, TimeOfDayAfternoon, TimeOfDayEvening, TimeOfDayNight}
+	facets.DepartureTimes = times.buckets(func(a, b FacetBucket) int {
+		return cmp.Compare(slices.Index(order, a.Value), slices.Index(order, b.Value))
+	})
+	if rng, ok := flightPriceRange(flights); ok {
+		facets.PriceRange = &rng
+		facets.PriceHistogram = priceHistogram(flights, rng.Min, rng.Max)
+	}
+	facets.Duration = &duration
+	return facets
+}
+
+// priceHistogram splits lowest..highest into at most priceHistogramBuckets
+// equal-width buckets; empty buckets are listed too. A bucket holds prices
+// from its Min up to, but not including, its Max, except the last one, which
+// ends at highest.
+func priceHistogram(flights []entity.Flight, lowest, highest int) []PriceBucket {
+	span := highest - lowest
+	width := max((span+priceHistogramBuckets-1)/priceHistogramBuckets, 1)
+	count := max((span+width-1)/width, 1)
+
+	buckets := make([]PriceBucket, count)
+	for i := range buckets {
+		buckets[i].Min = lowest + i*width
+		buckets[i].Max = min(lowest+(i+1)*width, highest)
+	}
+	for _, f := range flights {
+		i := min((f.Price.Amount-lowest)/width, count-1)
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+type facetCounter struct {
+	index   map[string]int
+	entries []FacetBucket
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{index: map[string]int{}}
+}
+
+func (c *facetCounter) add(value, label string, price int) {
+	i, ok := c.index[value]
+	if !ok {
+		c.index[value] = len(c.entries)
+		c.entries = append(c.entries, FacetBucket{Value: value, Label: label, MinPrice: price})
+		i = len(c.entries) - 1
+	}
+	c.entries[i].Count++
+	c.entries[i].MinPrice = min(c.entries[i].MinPrice, price)
+}
+
+func (c *facetCounter) buckets(compare func(a, b FacetBucket) int) []FacetBucket {
+	slices.SortFunc(c.entries, func(a, b FacetBucket) int {
+		return cmp.Or(compare(a, b), cmp.Compare(a.Value, b.Value))
+	})
+	return c.entries
+}
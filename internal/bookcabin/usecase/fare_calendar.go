@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+type FareCalendarInput struct {
+	Search   FlightsInput
+	FlexDays int
+}
+
+type FareCalendarOutput struct {
+	SearchCriteria SearchCriteria
+	Metadata       FareCalendarMetadata
+	Days           []FareCalendarDay
+}
+
+type FareCalendarMetadata struct {
+	From            string
+	To              string
+	TotalDays       int
+	DaysWithResults int
+	CheapestDate    *string
+	CacheHits       int
+	Partial         bool
+	SearchTimeMs    int64
+}
+
+type FareCalendarDay struct {
+	Date            string
+	Results         int
+	LowestPrice     int
+	LowestTotal     int
+	Currency        string
+	CheapestAirline *entity.Airline
+	CheapestFlight  string
+	CacheHit        bool
+	Partial         bool
+}
+
+// FareCalendar runs the regular one-way search for every day within FlexDays
+// of the departure date, at most calendarConcurrency days at a time. Each day
+// is a separate search, so it is cached under its own key and shared with
+// /flights.
+func (u *Usecase) FareCalendar(ctx context.Context, in FareCalendarInput) (*FareCalendarOutput, error) {
+	start := time.Now()
+	in.Search.Currency = u.resolveCurrency(in.Search.Currency)
+	if err := u.validateCurrency(ctx, in.Search.Currency); err != nil {
+		return nil, err
+	}
+
+	center := in.Search.DepartureDate
+	days := make([]FareCalendarDay, 2*in.FlexDays+1)
+	errs := make([]error, len(days))
+	sem := make(chan struct{}, max(u.calendarConcurrency, 1))
+
+	var wg sync.WaitGroup
+	for i := range days {
+		date := center.AddDate(0, 0, i-in.FlexDays)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			search := in.Search
+			search.DepartureDate = date
+			search.ReturnDate = nil
+			search.Pairing = nil
+			search.Limit = 0
+			search.Filters = shiftFiltersDate(in.Search.Filters, date)
+
+			output, err := u.Flights(ctx, search)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			days[i] = buildFareCalendarDay(date, output)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	criteria := SearchCriteria{
+		Origin:        in.Search.Origin,
+		Destination:   in.Search.Destination,
+		DepartureDate: center.Format("2006-01-02"),
+		Passengers:    in.Search.Passengers,
+		CabinClass:    in.Search.CabinClass,
+		Currency:      in.Search.Currency,
+	}
+
+	return &FareCalendarOutput{
+		SearchCriteria: criteria,
+		Metadata:       buildFareCalendarMetadata(days, start),
+		Days:           days,
+	}, nil
+}
+
+func buildFareCalendarDay(date time.Time, output *FlightsOutput) FareCalendarDay {
+	day := FareCalendarDay{
+		Date:     date.Format("2006-01-02"),
+		Results:  len(output.Flights),
+		Currency: output.SearchCriteria.Currency,
+		CacheHit: output.Metadata.CacheHit,
+		Partial:  output.Metadata.Partial,
+	}
+	if len(output.Flights) == 0 {
+		return day
+	}
+
+	cheapest := output.Flights[0]
+	for _, flight := range output.Flights[1:] {
+		if flight.Price.Amount < cheapest.Price.Amount {
+			cheapest = flight
+		}
+	}
+	airline := cheapest.Airline
+	day.LowestPrice = cheapest.Price.Amount
+	day.LowestTotal = cheapest.Price.Total
+	day.CheapestAirline = &airline
+	day.CheapestFlight = cheapest.ID
+	return day
+}
+
+func buildFareCalendarMetadata(days []FareCalendarDay, start time.Time) FareCalendarMetadata {
+	meta := FareCalendarMetadata{TotalDays: len(days)}
+	if len(days) > 0 {
+		meta.From = days[0].Date
+		meta.To = days[len(days)-1].Date
+	}
+
+	var cheapest *FareCalendarDay
+	for i := range days {
+		day := &days[i]
+		if day.CacheHit {
+			meta.CacheHits++
+		}
+		if day.Partial {
+			meta.Partial = true
+		}
+		if day.Results == 0 {
+			continue
+		}
+		meta.DaysWithResults++
+		if cheapest == nil || day.LowestPrice < cheapest.LowestPrice {
+			cheapest = day
+		}
+	}
+	if cheapest != nil {
+		date := cheapest.Date
+		meta.CheapestDate = &date
+	}
+	meta.SearchTimeMs = time.Since(start).Milliseconds()
+
+	return meta
+}
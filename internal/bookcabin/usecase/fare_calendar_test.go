@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+func TestFareCalendar(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(14, 6), 1250000),
+		stubFlight("JT740", "CGK", "DPS", at(14, 19), 980000),
+		stubFlight("QZ7510", "CGK", "DPS", at(16, 7), 720000),
+		stubFlight("ID6520", "CGK", "DPS", at(17, 9), 720000),
+		stubFlight("GA410", "CGK", "DPS", at(19, 9), 500000),
+	}}
+	u := newTestUsecase(t, stub)
+	in := FareCalendarInput{Search: oneWayInput, FlexDays: 2}
+
+	out, err := u.FareCalendar(context.Background(), in)
+	if err != nil {
+		t.Fatalf("FareCalendar: %v", err)
+	}
+
+	dates := make([]string, 0, len(out.Days))
+	for _, day := range out.Days {
+		dates = append(dates, day.Date)
+	}
+	if want := []string{"2025-12-13", "2025-12-14", "2025-12-15", "2025-12-16", "2025-12-17"}; !reflect.DeepEqual(dates, want) {
+		t.Fatalf("expected the window %v, got %v", want, dates)
+	}
+	meta := out.Metadata
+	if meta.From != "2025-12-13" || meta.To != "2025-12-17" || meta.TotalDays != 5 || meta.DaysWithResults != 3 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	// 16 and 17 tie on price; the earlier date wins.
+	if meta.CheapestDate == nil || *meta.CheapestDate != "2025-12-16" {
+		t.Fatalf("expected 2025-12-16 to be the cheapest date, got %v", meta.CheapestDate)
+	}
+
+	day := out.Days[1]
+	if day.Results != 2 || day.LowestPrice != 980000 || day.CheapestFlight != "JT740" || day.CheapestAirline == nil || day.CheapestAirline.Code != "JT" {
+		t.Fatalf("expected JT740 to be the cheapest flight on the 14th, got %+v", day)
+	}
+	if empty := out.Days[0]; empty.Results != 0 || empty.CheapestAirline != nil {
+		t.Fatalf("expected an empty day without a cheapest flight, got %+v", empty)
+	}
+
+	again, err := u.FareCalendar(context.Background(), in)
+	if err != nil {
+		t.Fatalf("FareCalendar: %v", err)
+	}
+	if again.Metadata.CacheHits != 5 || stub.calls.Load() != 5 {
+		t.Fatalf("expected every day to come from the cache, got %d hits and %d calls", again.Metadata.CacheHits, stub.calls.Load())
+	}
+}
+
+func TestFareCalendarConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	stub := &stubProvider{search: func(context.Context, provider.SearchRequest) ([]entity.Flight, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		return nil, nil
+	}}
+	dep := testDependency(stub)
+	dep.CalendarConcurrency = 2
+	u := newUsecaseFrom(t, dep)
+
+	out, err := u.FareCalendar(context.Background(), FareCalendarInput{Search: oneWayInput, FlexDays: 3})
+	if err != nil {
+		t.Fatalf("FareCalendar: %v", err)
+	}
+	if len(out.Days) != 7 || stub.calls.Load() != 7 {
+		t.Fatalf("expected 7 searched days, got %d days and %d calls", len(out.Days), stub.calls.Load())
+	}
+	if got := peak.Load(); got != 2 {
+		t.Fatalf("expected at most 2 days in flight and the limit to be used, got a peak of %d", got)
+	}
+}
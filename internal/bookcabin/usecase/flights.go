@@ -5,8 +5,10 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
 )
@@ -16,29 +18,43 @@ type FlightsInput struct {
 	Destination   string
 	DepartureDate time.Time
 	ReturnDate    *time.Time
-	Passengers    int
+	Passengers    entity.Passengers
 	CabinClass    string
 	Filters       FlightFilters
-	Sort          SortOption
+	Sort          []SortOption
+	Pairing       *PairingOption
+	Currency      string
+	Dedup         string
+	Scoring       ScoreOptions
+	// Limit pages the result set when set; the first page comes back with
+	// a cursor for the next one.
+	Limit int
+
+	OriginRadiusKm      float64
+	DestinationRadiusKm float64
 }
 
 type FlightFilters struct {
-	MinPrice     *int
-	MaxPrice     *int
-	Stops        *int
-	MaxStops     *int
-	MinDuration  *int
-	MaxDuration  *int
-	Airlines     []string
-	DepartAfter  *time.Time
-	DepartBefore *time.Time
-	ArriveAfter  *time.Time
-	ArriveBefore *time.Time
-}
-
-type SortOption struct {
-	Field string
-	Order string
+	MinPrice       *float64
+	MaxPrice       *float64
+	PriceBasis     string
+	Stops          *int
+	MaxStops       *int
+	MinDuration    *int
+	MaxDuration    *int
+	MinLayover     *int
+	MaxLayover     *int
+	Via            []string
+	Airlines       []string
+	AirlineTypes   []string
+	Alliances      []string
+	Amenities      []string
+	CheckedBaggage string
+	MinCheckedKg   *int
+	DepartAfter    *TimeBound
+	DepartBefore   *TimeBound
+	ArriveAfter    *TimeBound
+	ArriveBefore   *TimeBound
 }
 
 type FlightsOutput struct {
@@ -46,190 +62,456 @@ type FlightsOutput struct {
 	Metadata       SearchMetadata
 	Flights        []entity.Flight
 	ReturnFlights  []entity.Flight
+	Itineraries    []entity.Itinerary
+	Facets         Facets
+	ReturnFacets   *Facets
 }
 
 type SearchCriteria struct {
-	Origin        string
-	Destination   string
-	DepartureDate string
-	ReturnDate    *string
-	Passengers    int
-	CabinClass    string
+	Origin              string
+	Destination         string
+	OriginAirports      []string
+	DestinationAirports []string
+	DepartureDate       string
+	ReturnDate          *string
+	Passengers          entity.Passengers
+	CabinClass          string
+	Currency            string
 }
 
 type SearchMetadata struct {
 	TotalResults       int
+	TotalBeforeFilters int
 	ProvidersQueried   int
 	ProvidersSucceeded int
 	ProvidersFailed    int
+	ProvidersPending   int
 	SearchTimeMs       int64
 	CacheHit           bool
-	FailedProviders    []string
+	Partial            bool
+	FailedProviders    []FailedProvider
+	PendingProviders   []string
+	Providers          []ProviderStatus
+	Offset             int
+	Limit              int
+	NextCursor         *string
+	NextReturnCursor   *string
 }
 
+type FailedProvider struct {
+	Name   string
+	Reason string
+}
+
+const (
+	PriceBasisPerPassenger = "per_passenger"
+	PriceBasisTotal        = "total"
+
+	CheckedBaggageIncluded    = "included"
+	CheckedBaggageNotIncluded = "not_included"
+)
+
+// Dedup modes decide what happens to copies of one operating flight: grouped
+// (the default) keeps the cheapest and lists the others as offers, cheapest
+// drops the others, and off returns every copy.
+const (
+	DedupGrouped  = "grouped"
+	DedupCheapest = "cheapest"
+	DedupOff      = "off"
+)
+
 var errProviderFailed = errors.New("provider search failed")
 
 func (u *Usecase) Flights(ctx context.Context, in FlightsInput) (*FlightsOutput, error) {
+	in.Currency = u.resolveCurrency(in.Currency)
+	output, err := u.search(ctx, in, nil)
+	if err != nil || in.Limit <= 0 {
+		return output, err
+	}
+	return u.firstPage(ctx, in, output), nil
+}
+
+func (u *Usecase) search(ctx context.Context, in FlightsInput, stream *flightStream) (*FlightsOutput, error) {
 	start := time.Now()
+	in.Currency = u.resolveCurrency(in.Currency)
+	if err := u.validateCurrency(ctx, in.Currency); err != nil {
+		return nil, err
+	}
+	origins, destinations, pairs, err := u.airportPairs(in)
+	if err != nil {
+		return nil, err
+	}
+
 	cacheKey := buildCacheKey(in)
 	if cached, ok := u.cache.Get(cacheKey); ok {
 		cached.Metadata.CacheHit = true
 		cached.Metadata.SearchTimeMs = time.Since(start).Milliseconds()
+		stream.cached(cached)
 		return cached, nil
 	}
 
-	outboundReq := provider.SearchRequest{
-		Origin:        in.Origin,
-		Destination:   in.Destination,
-		DepartureDate: in.DepartureDate,
-		Passengers:    in.Passengers,
-		CabinClass:    in.CabinClass,
-	}
-	outboundFlights, outboundStats := u.collectFlights(ctx, outboundReq, in, in.Origin, in.Destination, in.DepartureDate, in.Filters)
-	applyBestValueScore(outboundFlights)
-	sortFlights(outboundFlights, in.Sort)
+	outbound, inbound := u.searchLegs(ctx, in, pairs, u.searchDeadlineFrom(start), stream)
+	outboundFlights, returnFlights := outbound.flights, inbound.flights
 
-	returnFlights := []entity.Flight{}
-	returnStats := providerStats{}
-	if in.ReturnDate != nil {
-		returnFilters := shiftFiltersDate(in.Filters, *in.ReturnDate)
-		returnReq := provider.SearchRequest{
-			Origin:        in.Destination,
-			Destination:   in.Origin,
-			DepartureDate: *in.ReturnDate,
-			Passengers:    in.Passengers,
-			CabinClass:    in.CabinClass,
-		}
-		returnFlights, returnStats = u.collectFlights(ctx, returnReq, in, in.Destination, in.Origin, *in.ReturnDate, returnFilters)
-		applyBestValueScore(returnFlights)
-		sortFlights(returnFlights, in.Sort)
+	itineraries := []entity.Itinerary{}
+	if in.ReturnDate != nil && in.Pairing != nil {
+		itineraries = u.buildItineraries(outboundFlights, returnFlights, *in.Pairing, in)
 	}
 
-	providersSucceeded, failedProviders := mergeProviderStats(u.providers, outboundStats, returnStats)
+	merged := mergeProviderStats(u.providers, outbound.stats, inbound.stats)
+	statuses := append(outbound.stats.statuses, inbound.stats.statuses...)
 
 	searchCriteria := SearchCriteria{
-		Origin:        in.Origin,
-		Destination:   in.Destination,
-		DepartureDate: in.DepartureDate.Format("2006-01-02"),
-		Passengers:    in.Passengers,
-		CabinClass:    in.CabinClass,
-	}
+		Origin:              in.Origin,
+		Destination:         in.Destination,
+		OriginAirports:      origins,
+		DestinationAirports: destinations,
+		DepartureDate:       in.DepartureDate.Format("2006-01-02"),
+		Passengers:          in.Passengers,
+		CabinClass:          in.CabinClass,
+		Currency:            in.Currency,
+	}
+	var returnFacets *Facets
 	if in.ReturnDate != nil {
 		value := in.ReturnDate.Format("2006-01-02")
 		searchCriteria.ReturnDate = &value
+		returnFacets = &inbound.facets
 	}
 
 	output := &FlightsOutput{
 		SearchCriteria: searchCriteria,
 		Metadata: SearchMetadata{
 			TotalResults:       len(outboundFlights) + len(returnFlights),
+			TotalBeforeFilters: totalProviderResults(statuses),
 			ProvidersQueried:   len(u.providers),
-			ProvidersSucceeded: providersSucceeded,
-			ProvidersFailed:    len(u.providers) - providersSucceeded,
+			ProvidersSucceeded: merged.succeeded,
+			ProvidersFailed:    len(merged.failed),
+			ProvidersPending:   len(merged.pending),
 			SearchTimeMs:       time.Since(start).Milliseconds(),
 			CacheHit:           false,
-			FailedProviders:    failedProviders,
+			Partial:            hasPendingStatus(statuses),
+			FailedProviders:    merged.failed,
+			PendingProviders:   merged.pending,
+			Providers:          statuses,
 		},
 		Flights:       outboundFlights,
 		ReturnFlights: returnFlights,
+		Itineraries:   itineraries,
+		Facets:        outbound.facets,
+		ReturnFacets:  returnFacets,
 	}
 
-	u.cache.Set(cacheKey, output, u.cacheTTL)
+	// Partial results would hide late providers for the whole TTL, so only
+	// complete searches are cached. The same goes for canceled searches, whose
+	// providers all fail with the context error.
+	if !output.Metadata.Partial && ctx.Err() == nil {
+		u.cache.Set(cacheKey, output, u.cacheTTL)
+	}
 
 	return output, nil
 }
 
+func (u *Usecase) searchLegs(
+	ctx context.Context,
+	in FlightsInput,
+	pairs []airportPair,
+	deadline time.Time,
+	stream *flightStream,
+) (legResult, legResult) {
+	outboundReqs := pairRequests(pairs, provider.SearchRequest{
+		DepartureDate: in.DepartureDate,
+		Passengers:    in.Passengers,
+		CabinClass:    in.CabinClass,
+	}, false)
+
+	inbound := legResult{flights: []entity.Flight{}}
+	var wg sync.WaitGroup
+	if in.ReturnDate != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			returnFilters := shiftFiltersDate(in.Filters, *in.ReturnDate)
+			returnReqs := pairRequests(pairs, provider.SearchRequest{
+				DepartureDate: *in.ReturnDate,
+				Passengers:    in.Passengers,
+				CabinClass:    in.CabinClass,
+			}, true)
+			inbound = u.collectFlights(ctx, returnReqs, in, LegReturn, deadline, returnFilters, stream)
+			u.applyBestValueScore(inbound.flights, in.Scoring)
+			sortFlights(inbound.flights, in.Sort)
+		}()
+	}
+
+	outbound := u.collectFlights(ctx, outboundReqs, in, LegOutbound, deadline, in.Filters, stream)
+	u.applyBestValueScore(outbound.flights, in.Scoring)
+	sortFlights(outbound.flights, in.Sort)
+
+	wg.Wait()
+	return outbound, inbound
+}
+
 type providerResult struct {
-	name    string
+	name     string
+	flights  []entity.Flight
+	err      error
+	attempts int
+	hedged   bool
+	pending  bool
+	latency  time.Duration
+}
+
+// legResult is one leg of a search: the flights left after filtering, the
+// facets of the flights before filtering, and how the providers did.
+type legResult struct {
 	flights []entity.Flight
-	err     error
+	facets  Facets
+	stats   providerStats
 }
 
 type providerStats struct {
-	success map[string]bool
-	failed  map[string]bool
+	success  map[string]bool
+	failed   map[string]string
+	pending  map[string]bool
+	statuses []ProviderStatus
 }
 
-func (u *Usecase) searchProviders(ctx context.Context, req provider.SearchRequest) []providerResult {
+func (u *Usecase) searchProviders(
+	ctx context.Context,
+	req provider.SearchRequest,
+	deadline time.Time,
+	onResult func(providerResult),
+) []providerResult {
+	start := time.Now()
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	results := make([]providerResult, 0, len(u.providers))
 	resCh := make(chan providerResult, len(u.providers))
-
 	for _, p := range u.providers {
 		providerItem := p
 		go func() {
-			providerCtx, cancel := context.WithTimeout(ctx, u.providerTimeout)
+			providerCtx, cancel := context.WithTimeout(searchCtx, u.providerTimeoutFor(providerItem.Name()))
 			defer cancel()
-			flights, err := u.searchWithRetry(providerCtx, providerItem, req)
-			resCh <- providerResult{name: providerItem.Name(), flights: flights, err: err}
+			started := time.Now()
+			res := u.searchWithRetry(providerCtx, providerItem, req)
+			res.latency = time.Since(started)
+			resCh <- res
 		}()
 	}
 
-	for i := 0; i < len(u.providers); i++ {
-		results = append(results, <-resCh)
+	var expired <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	done := make(map[string]bool, len(u.providers))
+	for len(results) < len(u.providers) {
+		select {
+		case res := <-resCh:
+			done[res.name] = true
+			results = append(results, res)
+			if onResult != nil {
+				onResult(res)
+			}
+		case <-expired:
+			for _, p := range u.providers {
+				if done[p.Name()] {
+					continue
+				}
+				res := providerResult{name: p.Name(), pending: true, latency: time.Since(start)}
+				results = append(results, res)
+				if onResult != nil {
+					onResult(res)
+				}
+			}
+			return results
+		}
 	}
 
 	return results
 }
 
+// collectFlights searches every airport pair of a leg in parallel and merges
+// the results. A provider counts as succeeded when any pair succeeded.
 func (u *Usecase) collectFlights(
+	ctx context.Context,
+	reqs []provider.SearchRequest,
+	in FlightsInput,
+	leg string,
+	deadline time.Time,
+	filters FlightFilters,
+	stream *flightStream,
+) legResult {
+	flights := make([]entity.Flight, 0)
+	unfiltered := make([]entity.Flight, 0)
+	stats := providerStats{
+		success:  map[string]bool{},
+		failed:   map[string]string{},
+		pending:  map[string]bool{},
+		statuses: make([]ProviderStatus, 0, len(reqs)*len(u.providers)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			routeFlights, routeUnfiltered, routeStats := u.collectRoute(ctx, req, in, leg, deadline, filters, stream)
+
+			mu.Lock()
+			defer mu.Unlock()
+			flights = append(flights, routeFlights...)
+			unfiltered = append(unfiltered, routeUnfiltered...)
+			stats.statuses = append(stats.statuses, routeStats.statuses...)
+			for name := range routeStats.success {
+				stats.success[name] = true
+			}
+			for name := range routeStats.pending {
+				stats.pending[name] = true
+			}
+			for name, reason := range routeStats.failed {
+				if _, ok := stats.failed[name]; !ok {
+					stats.failed[name] = reason
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(stats.statuses, func(i, j int) bool {
+		if stats.statuses[i].Name != stats.statuses[j].Name {
+			return stats.statuses[i].Name < stats.statuses[j].Name
+		}
+		return stats.statuses[i].Route < stats.statuses[j].Route
+	})
+	if len(reqs) > 1 {
+		flights = compareAndDedupFlights(flights, in.Dedup)
+		unfiltered = compareAndDedupFlights(unfiltered, in.Dedup)
+	}
+	return legResult{flights: flights, facets: buildFacets(unfiltered, in.Currency), stats: stats}
+}
+
+func (u *Usecase) collectRoute(
 	ctx context.Context,
 	req provider.SearchRequest,
 	in FlightsInput,
-	origin string,
-	destination string,
-	date time.Time,
+	leg string,
+	deadline time.Time,
 	filters FlightFilters,
-) ([]entity.Flight, providerStats) {
-	results := u.searchProviders(ctx, req)
+	stream *flightStream,
+) ([]entity.Flight, []entity.Flight, providerStats) {
+	route := req.Origin + "-" + req.Destination
+	statusOf := func(res providerResult) ProviderStatus {
+		status := newProviderStatus(res, leg)
+		status.Route = route
+		return status
+	}
+
+	var onResult func(providerResult)
+	if stream != nil {
+		onResult = func(res providerResult) {
+			flights, _ := u.prepareFlights(ctx, res.flights, req, in, filters)
+			stream.provider(statusOf(res), flights)
+		}
+	}
+
+	results := u.searchProviders(ctx, req, deadline, onResult)
 	flights := make([]entity.Flight, 0)
-	stats := providerStats{success: map[string]bool{}, failed: map[string]bool{}}
+	stats := providerStats{
+		success:  map[string]bool{},
+		failed:   map[string]string{},
+		pending:  map[string]bool{},
+		statuses: make([]ProviderStatus, 0, len(results)),
+	}
 	for _, res := range results {
+		status := statusOf(res)
+		logProviderStatus(ctx, status, res.err)
+		stats.statuses = append(stats.statuses, status)
+		if res.pending {
+			stats.pending[res.name] = true
+			continue
+		}
 		if res.err != nil {
-			stats.failed[res.name] = true
+			stats.failed[res.name] = status.ErrorClass
 			continue
 		}
 		stats.success[res.name] = true
 		flights = append(flights, res.flights...)
 	}
+	filtered, unfiltered := u.prepareFlights(ctx, flights, req, in, filters)
+	return filtered, unfiltered, stats
+}
+
+func (u *Usecase) prepareFlights(
+	ctx context.Context,
+	flights []entity.Flight,
+	req provider.SearchRequest,
+	in FlightsInput,
+	filters FlightFilters,
+) ([]entity.Flight, []entity.Flight) {
+	if len(flights) == 0 {
+		return []entity.Flight{}, []entity.Flight{}
+	}
 	flights = normalizeDurations(flights)
-	criteriaDate := date.Format("2006-01-02")
-	filtered := filterFlights(flights, origin, destination, in.CabinClass, filters, criteriaDate)
-	compared := compareAndDedupFlights(filtered)
-	return compared, stats
+	flights = u.convertPrices(ctx, flights, in.Currency)
+	flights = applyPassengerPricing(flights, in.Passengers)
+	criteriaDate := req.DepartureDate.Format("2006-01-02")
+	filters = u.localizeTimeFilters(filters, req)
+	filters.Airlines = u.resolveAirlineFilter(filters.Airlines)
+	filtered := filterFlights(flights, req.Origin, req.Destination, in.CabinClass, in.Passengers.Seats(), filters, criteriaDate)
+	// Facets are counted before the optional filters so they show what
+	// toggling a filter would yield; the search criteria still apply.
+	unfiltered := filterFlights(flights, req.Origin, req.Destination, in.CabinClass, in.Passengers.Seats(), FlightFilters{}, criteriaDate)
+	return compareAndDedupFlights(filtered, in.Dedup), compareAndDedupFlights(unfiltered, in.Dedup)
 }
 
-func (u *Usecase) searchWithRetry(ctx context.Context, p provider.Provider, req provider.SearchRequest) ([]entity.Flight, error) {
+func (u *Usecase) searchWithRetry(ctx context.Context, p provider.Provider, req provider.SearchRequest) providerResult {
+	res := providerResult{name: p.Name()}
 	backoff := 80 * time.Millisecond
-	for attempt := 0; attempt <= u.maxProviderRetries; attempt++ {
-		flights, err := p.Search(ctx, req)
+	retries := u.providerRetriesFor(p.Name())
+	for attempt := 0; attempt <= retries; attempt++ {
+		res.attempts = attempt + 1
+		flights, hedged, err := u.searchAttempt(ctx, p, req)
+		res.hedged = res.hedged || hedged
 		if err == nil {
-			return flights, nil
-		}
-		if !errors.Is(err, provider.ErrTemporary) {
-			return nil, err
+			res.flights = flights
+			return res
 		}
-		if attempt == u.maxProviderRetries {
-			return nil, err
+		res.err = err
+		if !errors.Is(err, provider.ErrTemporary) || attempt == retries {
+			return res
 		}
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			res.err = ctx.Err()
+			return res
 		case <-time.After(backoff):
 			backoff *= 2
 		}
 	}
-	return nil, errProviderFailed
+	res.err = errProviderFailed
+	return res
 }
 
-func filterFlights(flights []entity.Flight, origin, destination, cabinClass string, filters FlightFilters, criteriaDate string) []entity.Flight {
+func filterFlights(
+	flights []entity.Flight,
+	origin, destination, cabinClass string,
+	seats int,
+	filters FlightFilters,
+	criteriaDate string,
+) []entity.Flight {
 	filtered := make([]entity.Flight, 0, len(flights))
 	airlineFilter := normalizeSet(filters.Airlines)
+	viaFilter := normalizeSet(filters.Via)
 
 	for _, flight := range flights {
-		if !matchFlightCriteria(flight, origin, destination, cabinClass, criteriaDate) {
+		if !matchFlightCriteria(flight, origin, destination, cabinClass, seats, criteriaDate) {
 			continue
 		}
-		if !matchFilter(flight, filters, airlineFilter) {
+		if !matchFilter(flight, filters, airlineFilter, viaFilter) {
 			continue
 		}
 		filtered = append(filtered, flight)
@@ -238,7 +520,7 @@ func filterFlights(flights []entity.Flight, origin, destination, cabinClass stri
 	return filtered
 }
 
-func matchFlightCriteria(f entity.Flight, origin, destination, cabinClass, criteriaDate string) bool {
+func matchFlightCriteria(f entity.Flight, origin, destination, cabinClass string, seats int, criteriaDate string) bool {
 	if !strings.EqualFold(f.Departure.Airport, origin) {
 		return false
 	}
@@ -257,6 +539,9 @@ func matchFlightCriteria(f entity.Flight, origin, destination, cabinClass, crite
 	if cabinClass != "" && !strings.EqualFold(f.CabinClass, cabinClass) {
 		return false
 	}
+	if f.AvailableSeats < seats {
+		return false
+	}
 	return true
 }
 
@@ -269,29 +554,44 @@ func shiftFiltersDate(filters FlightFilters, date time.Time) FlightFilters {
 	return clone
 }
 
-func shiftTimeDate(value *time.Time, date time.Time) *time.Time {
-	if value == nil {
-		return nil
+// shiftTimeDate moves an absolute bound to date. Local bounds already follow
+// the date of the leg they are applied to.
+func shiftTimeDate(value *TimeBound, date time.Time) *TimeBound {
+	if value == nil || value.Local {
+		return value
 	}
-	shifted := time.Date(date.Year(), date.Month(), date.Day(), value.Hour(), value.Minute(), value.Second(), value.Nanosecond(), value.Location())
-	return &shifted
+	at := value.Instant
+	shifted := time.Date(date.Year(), date.Month(), date.Day(), at.Hour(), at.Minute(), at.Second(), at.Nanosecond(), at.Location())
+	return &TimeBound{Instant: shifted}
 }
 
-func mergeProviderStats(providers []provider.Provider, outbound providerStats, inbound providerStats) (int, []string) {
-	succeeded := 0
-	failedProviders := make([]string, 0)
+type mergedProviderStats struct {
+	succeeded int
+	failed    []FailedProvider
+	pending   []string
+}
+
+func mergeProviderStats(providers []provider.Provider, outbound providerStats, inbound providerStats) mergedProviderStats {
+	merged := mergedProviderStats{failed: make([]FailedProvider, 0), pending: make([]string, 0)}
 	for _, p := range providers {
 		name := p.Name()
-		if outbound.success[name] || inbound.success[name] {
-			succeeded++
-			continue
+		switch {
+		case outbound.success[name] || inbound.success[name]:
+			merged.succeeded++
+		case outbound.pending[name] || inbound.pending[name]:
+			merged.pending = append(merged.pending, name)
+		default:
+			reason := outbound.failed[name]
+			if reason == "" {
+				reason = inbound.failed[name]
+			}
+			merged.failed = append(merged.failed, FailedProvider{Name: name, Reason: reason})
 		}
-		failedProviders = append(failedProviders, name)
 	}
-	return succeeded, failedProviders
+	return merged
 }
 
-func matchFilter(f entity.Flight, filters FlightFilters, airlineFilter map[string]struct{}) bool {
+func matchFilter(f entity.Flight, filters FlightFilters, airlineFilter, viaFilter map[string]struct{}) bool {
 	if !matchPriceFilter(f, filters) {
 		return false
 	}
@@ -301,17 +601,27 @@ func matchFilter(f entity.Flight, filters FlightFilters, airlineFilter map[strin
 	if !matchDurationFilter(f, filters) {
 		return false
 	}
-	if !matchAirlineFilter(f, airlineFilter) {
+	if !matchAirlineFilter(f, filters, airlineFilter) {
+		return false
+	}
+	if !matchLayoverFilter(f, filters, viaFilter) {
+		return false
+	}
+	if !matchServiceFilter(f, filters) {
 		return false
 	}
 	return matchTimeFilter(f, filters)
 }
 
 func matchPriceFilter(f entity.Flight, filters FlightFilters) bool {
-	if filters.MinPrice != nil && f.Price.Amount < *filters.MinPrice {
+	amount := currency.ToMajor(f.Price.Amount, f.Price.Currency)
+	if filters.PriceBasis == PriceBasisTotal {
+		amount = currency.ToMajor(f.Price.Total, f.Price.Currency)
+	}
+	if filters.MinPrice != nil && amount < *filters.MinPrice {
 		return false
 	}
-	if filters.MaxPrice != nil && f.Price.Amount > *filters.MaxPrice {
+	if filters.MaxPrice != nil && amount > *filters.MaxPrice {
 		return false
 	}
 	return true
@@ -337,107 +647,39 @@ func matchDurationFilter(f entity.Flight, filters FlightFilters) bool {
 	return true
 }
 
-func matchAirlineFilter(f entity.Flight, airlineFilter map[string]struct{}) bool {
-	if len(airlineFilter) == 0 {
-		return true
-	}
-	if _, ok := airlineFilter[strings.ToLower(f.Airline.Name)]; ok {
-		return true
-	}
-	if _, ok := airlineFilter[strings.ToLower(f.Airline.Code)]; ok {
-		return true
+func matchLayoverFilter(f entity.Flight, filters FlightFilters, viaFilter map[string]struct{}) bool {
+	viaMatched := len(viaFilter) == 0
+	for i := 1; i < len(f.Segments); i++ {
+		layover := f.Segments[i].LayoverMinute
+		if filters.MinLayover != nil && layover < *filters.MinLayover {
+			return false
+		}
+		if filters.MaxLayover != nil && layover > *filters.MaxLayover {
+			return false
+		}
+		if _, ok := viaFilter[strings.ToLower(f.Segments[i].Departure.Airport)]; ok {
+			viaMatched = true
+		}
 	}
-	return false
+	return viaMatched
 }
 
 func matchTimeFilter(f entity.Flight, filters FlightFilters) bool {
-	if filters.DepartAfter != nil && f.Departure.Time.Before(*filters.DepartAfter) {
+	if filters.DepartAfter != nil && f.Departure.Time.Before(filters.DepartAfter.Instant) {
 		return false
 	}
-	if filters.DepartBefore != nil && f.Departure.Time.After(*filters.DepartBefore) {
+	if filters.DepartBefore != nil && f.Departure.Time.After(filters.DepartBefore.Instant) {
 		return false
 	}
-	if filters.ArriveAfter != nil && f.Arrival.Time.Before(*filters.ArriveAfter) {
+	if filters.ArriveAfter != nil && f.Arrival.Time.Before(filters.ArriveAfter.Instant) {
 		return false
 	}
-	if filters.ArriveBefore != nil && f.Arrival.Time.After(*filters.ArriveBefore) {
+	if filters.ArriveBefore != nil && f.Arrival.Time.After(filters.ArriveBefore.Instant) {
 		return false
 	}
 	return true
 }
 
-func applyBestValueScore(flights []entity.Flight) {
-	if len(flights) == 0 {
-		return
-	}
-	minPrice, maxPrice := flights[0].Price.Amount, flights[0].Price.Amount
-	minDuration, maxDuration := flights[0].DurationMinute, flights[0].DurationMinute
-	for _, f := range flights[1:] {
-		if f.Price.Amount < minPrice {
-			minPrice = f.Price.Amount
-		}
-		if f.Price.Amount > maxPrice {
-			maxPrice = f.Price.Amount
-		}
-		if f.DurationMinute < minDuration {
-			minDuration = f.DurationMinute
-		}
-		if f.DurationMinute > maxDuration {
-			maxDuration = f.DurationMinute
-		}
-	}
-
-	priceRange := float64(maxPrice - minPrice)
-	durationRange := float64(maxDuration - minDuration)
-	if priceRange == 0 {
-		priceRange = 1
-	}
-	if durationRange == 0 {
-		durationRange = 1
-	}
-
-	for i := range flights {
-		priceScore := float64(flights[i].Price.Amount-minPrice) / priceRange
-		durationScore := float64(flights[i].DurationMinute-minDuration) / durationRange
-		flights[i].BestValueScore = priceScore*0.6 + durationScore*0.4
-	}
-}
-
-func sortFlights(flights []entity.Flight, sortOpt SortOption) {
-	field := strings.ToLower(sortOpt.Field)
-	order := strings.ToLower(sortOpt.Order)
-	if field == "" {
-		field = "best_value"
-	}
-	if order == "" {
-		order = "asc"
-	}
-
-	less := func(i, j int) bool {
-		switch field {
-		case "price":
-			return flights[i].Price.Amount < flights[j].Price.Amount
-		case "duration":
-			return flights[i].DurationMinute < flights[j].DurationMinute
-		case "departure":
-			return flights[i].Departure.Time.Before(flights[j].Departure.Time)
-		case "arrival":
-			return flights[i].Arrival.Time.Before(flights[j].Arrival.Time)
-		case "best_value":
-			return flights[i].BestValueScore < flights[j].BestValueScore
-		default:
-			return flights[i].BestValueScore < flights[j].BestValueScore
-		}
-	}
-
-	if order == "desc" {
-		sort.SliceStable(flights, func(i, j int) bool { return !less(i, j) })
-		return
-	}
-
-	sort.SliceStable(flights, less)
-}
-
 func normalizeSet(values []string) map[string]struct{} {
 	if len(values) == 0 {
 		return nil
@@ -453,6 +695,25 @@ func normalizeSet(values []string) map[string]struct{} {
 	return set
 }
 
+func applyPassengerPricing(flights []entity.Flight, pax entity.Passengers) []entity.Flight {
+	if pax.Total() == 0 {
+		pax.Adults = 1
+	}
+	for i := range flights {
+		price := &flights[i].Price
+		price.Passengers = pax.Total()
+		if len(price.Fares) == 0 {
+			price.Total = price.Amount * pax.Total()
+			continue
+		}
+		price.Total = 0
+		for _, fare := range price.Fares {
+			price.Total += fare.Total
+		}
+	}
+	return flights
+}
+
 func normalizeDurations(flights []entity.Flight) []entity.Flight {
 	for i := range flights {
 		if flights[i].Departure.Time.IsZero() || flights[i].Arrival.Time.IsZero() {
@@ -468,34 +729,96 @@ func normalizeDurations(flights []entity.Flight) []entity.Flight {
 	return flights
 }
 
-func compareAndDedupFlights(flights []entity.Flight) []entity.Flight {
-	if len(flights) == 0 {
+// compareAndDedupFlights groups the flights sharing one operating flight,
+// codeshares included, and keeps the cheapest of each group. In grouped mode
+// the others are attached to the winner as offers.
+func compareAndDedupFlights(flights []entity.Flight, mode string) []entity.Flight {
+	if len(flights) == 0 || mode == DedupOff {
 		return flights
 	}
-	bestByKey := make(map[string]entity.Flight, len(flights))
+	groups := make(map[string][]entity.Flight, len(flights))
+	keys := make([]string, 0, len(flights))
 	for _, flight := range flights {
 		key := flightKey(flight)
-		current, ok := bestByKey[key]
-		if !ok || flight.Price.Amount < current.Price.Amount {
-			bestByKey[key] = flight
-			continue
-		}
-		if flight.Price.Amount == current.Price.Amount && flight.Provider < current.Provider {
-			bestByKey[key] = flight
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
 		}
+		groups[key] = append(groups[key], flight)
 	}
 
-	unique := make([]entity.Flight, 0, len(bestByKey))
-	for _, flight := range bestByKey {
-		unique = append(unique, flight)
+	unique := make([]entity.Flight, 0, len(groups))
+	for _, key := range keys {
+		winner := mergeOffers(groups[key])
+		if mode == DedupCheapest {
+			winner.Offers = nil
+		}
+		unique = append(unique, winner)
 	}
 	return unique
 }
 
+func mergeOffers(group []entity.Flight) entity.Flight {
+	best := 0
+	for i, flight := range group[1:] {
+		current := group[best]
+		if flight.Price.Amount < current.Price.Amount ||
+			(flight.Price.Amount == current.Price.Amount && flight.Provider < current.Provider) {
+			best = i + 1
+		}
+	}
+
+	winner := group[best]
+	offers := make([]entity.Offer, 0, len(winner.Offers)+len(group)-1)
+	seen := map[string]bool{winner.ID: true}
+	add := func(offer entity.Offer) {
+		if !seen[offer.FlightID] {
+			seen[offer.FlightID] = true
+			offers = append(offers, offer)
+		}
+	}
+	for i, flight := range group {
+		if i != best {
+			add(offerOf(flight))
+		}
+		for _, offer := range flight.Offers {
+			add(offer)
+		}
+	}
+	sort.SliceStable(offers, func(i, j int) bool {
+		if offers[i].Price.Amount != offers[j].Price.Amount {
+			return offers[i].Price.Amount < offers[j].Price.Amount
+		}
+		return offers[i].Provider < offers[j].Provider
+	})
+
+	winner.Offers = offers
+	return winner
+}
+
+func offerOf(f entity.Flight) entity.Offer {
+	return entity.Offer{
+		FlightID:       f.ID,
+		Provider:       f.Provider,
+		Airline:        f.Airline,
+		FlightNumber:   f.FlightNumber,
+		Price:          f.Price,
+		AvailableSeats: f.AvailableSeats,
+		Baggage:        f.Baggage,
+		CabinClass:     f.CabinClass,
+		FareClass:      f.FareClass,
+	}
+}
+
+// flightKey identifies the physical flight: the operating carrier and flight
+// number, falling back to the marketing ones, plus its airports and times.
 func flightKey(f entity.Flight) string {
+	airlineCode, flightNumber := f.OperatingAirline.Code, f.OperatingFlightNumber
+	if airlineCode == "" || flightNumber == "" {
+		airlineCode, flightNumber = f.Airline.Code, f.FlightNumber
+	}
 	return strings.ToLower(strings.Join([]string{
-		f.Airline.Code,
-		f.FlightNumber,
+		airlineCode,
+		flightNumber,
 		f.Departure.Airport,
 		f.Arrival.Airport,
 		f.Departure.Time.Format(time.RFC3339),
@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func TestApplyPassengerPricing(t *testing.T) {
+	tests := []struct {
+		name           string
+		pax            entity.Passengers
+		fares          []entity.PassengerFare
+		wantPassengers int
+		wantTotal      int
+	}{
+		{name: "single adult", pax: entity.Passengers{Adults: 1}, wantPassengers: 1, wantTotal: 780000},
+		{name: "flat fare for everyone", pax: entity.Passengers{Adults: 2, Children: 1}, wantPassengers: 3, wantTotal: 2340000},
+		{name: "missing count defaults to one adult", pax: entity.Passengers{}, wantPassengers: 1, wantTotal: 780000},
+		{
+			name: "per type fares are summed",
+			pax:  entity.Passengers{Adults: 2, Children: 1, Infants: 1},
+			fares: []entity.PassengerFare{
+				{Type: entity.PassengerAdult, Count: 2, Amount: 780000, Total: 1560000},
+				{Type: entity.PassengerChild, Count: 1, Amount: 585000, Total: 585000},
+				{Type: entity.PassengerInfant, Count: 1, Amount: 78000, Total: 78000},
+			},
+			wantPassengers: 4,
+			wantTotal:      2223000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flight := stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000)
+			flight.Price.Fares = tt.fares
+			price := applyPassengerPricing([]entity.Flight{flight}, tt.pax)[0].Price
+			if price.Amount != 780000 {
+				t.Fatalf("expected the adult amount to stay 780000, got %d", price.Amount)
+			}
+			if price.Passengers != tt.wantPassengers || price.Total != tt.wantTotal {
+				t.Fatalf("expected %d passengers and total %d, got %d and %d", tt.wantPassengers, tt.wantTotal, price.Passengers, price.Total)
+			}
+		})
+	}
+}
+
+func TestFlightsPriceBasis(t *testing.T) {
+	scarce := stubFlight("ID6512", "CGK", "DPS", at(15, 9), 500000)
+	scarce.AvailableSeats = 1
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000),
+		scarce,
+	}}
+	u := newTestUsecase(t, stub)
+	maxPrice := 2000000.0
+
+	tests := []struct {
+		basis   string
+		wantIDs []string
+	}{
+		{basis: "", wantIDs: []string{"JT740", "GA400"}},
+		{basis: PriceBasisPerPassenger, wantIDs: []string{"JT740", "GA400"}},
+		{basis: PriceBasisTotal, wantIDs: []string{"JT740"}},
+	}
+	for _, tt := range tests {
+		t.Run("basis "+tt.basis, func(t *testing.T) {
+			out, err := u.Flights(context.Background(), FlightsInput{
+				Origin:        "CGK",
+				Destination:   "DPS",
+				DepartureDate: at(15, 0),
+				Passengers:    entity.Passengers{Adults: 2},
+				Filters:       FlightFilters{MaxPrice: &maxPrice, PriceBasis: tt.basis},
+				Sort:          []SortOption{{Field: "price"}},
+			})
+			if err != nil {
+				t.Fatalf("Flights: %v", err)
+			}
+			ids := make([]string, 0, len(out.Flights))
+			for _, f := range out.Flights {
+				ids = append(ids, f.ID)
+				if f.Price.Total != 2*f.Price.Amount {
+					t.Fatalf("%s: expected the total to cover both passengers, got %+v", f.ID, f.Price)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestCompareAndDedupFlightsCodeshare(t *testing.T) {
+	operated := stubFlight("JT742", "CGK", "DPS", at(15, 11), 780000)
+	codeshare := stubFlight("ID7742", "CGK", "DPS", at(15, 11), 970000)
+	codeshare.Provider = "Batik Air"
+	codeshare.OperatingAirline = entity.Airline{Code: "JT", Name: "JT"}
+	codeshare.OperatingFlightNumber = "JT742"
+	other := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)
+
+	if flightKey(operated) != flightKey(codeshare) {
+		t.Fatalf("expected a codeshare to share the key of the operating flight, got %q and %q", flightKey(operated), flightKey(codeshare))
+	}
+
+	tests := []struct {
+		mode       string
+		wantIDs    []string
+		wantOffers int
+	}{
+		{mode: "", wantIDs: []string{"JT742", "GA400"}, wantOffers: 1},
+		{mode: DedupGrouped, wantIDs: []string{"JT742", "GA400"}, wantOffers: 1},
+		{mode: DedupCheapest, wantIDs: []string{"JT742", "GA400"}, wantOffers: 0},
+		{mode: DedupOff, wantIDs: []string{"ID7742", "GA400", "JT742"}, wantOffers: 0},
+	}
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			got := compareAndDedupFlights([]entity.Flight{codeshare, other, operated}, tt.mode)
+			ids := make([]string, 0, len(got))
+			for _, f := range got {
+				ids = append(ids, f.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, ids)
+			}
+			if len(got[0].Offers) != tt.wantOffers {
+				t.Fatalf("expected %d offers, got %+v", tt.wantOffers, got[0].Offers)
+			}
+			if tt.wantOffers > 0 && (got[0].Offers[0].FlightNumber != "ID7742" || got[0].Offers[0].Provider != "Batik Air") {
+				t.Fatalf("expected the codeshare as an offer, got %+v", got[0].Offers[0])
+			}
+			if len(got[1].Offers) != 0 {
+				t.Fatalf("expected no offers on a flight without duplicates, got %+v", got[1].Offers)
+			}
+		})
+	}
+}
+
+func TestCompareAndDedupFlightsMergesOffers(t *testing.T) {
+	cheap := stubFlight("JT742", "CGK", "DPS", at(15, 11), 780000)
+	cheap.Provider = "Lion Air"
+	pricey := stubFlight("JT742", "CGK", "DPS", at(15, 11), 820000)
+	pricey.ID = "JT742_Other"
+	pricey.Provider = "Other"
+	// A flight coming back from the cache already carries its offers.
+	pricey.Offers = []entity.Offer{{FlightID: "ID7742", Provider: "Batik Air", Price: entity.Price{Amount: 970000}}}
+
+	got := compareAndDedupFlights([]entity.Flight{pricey, cheap}, DedupGrouped)
+	if len(got) != 1 || got[0].ID != "JT742" {
+		t.Fatalf("expected the cheapest flight to win, got %+v", got)
+	}
+	var providers []string
+	for _, offer := range got[0].Offers {
+		providers = append(providers, offer.Provider)
+	}
+	if want := []string{"Other", "Batik Air"}; !reflect.DeepEqual(providers, want) {
+		t.Fatalf("expected offers ordered by price, got %v", providers)
+	}
+}
+
+func TestFlightsDedupModes(t *testing.T) {
+	lion := stubFlight("JT742", "CGK", "DPS", at(15, 11), 780000)
+	codeshare := stubFlight("ID7742", "CGK", "DPS", at(15, 11), 970000)
+	codeshare.OperatingAirline = entity.Airline{Code: "JT", Name: "JT"}
+	codeshare.OperatingFlightNumber = "JT742"
+	u := newTestUsecase(t, &stubProvider{flights: []entity.Flight{lion, codeshare}})
+
+	tests := []struct {
+		mode        string
+		wantFlights int
+		wantOffers  int
+	}{
+		{mode: DedupGrouped, wantFlights: 1, wantOffers: 1},
+		{mode: DedupCheapest, wantFlights: 1, wantOffers: 0},
+		{mode: DedupOff, wantFlights: 2, wantOffers: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			in := oneWayInput
+			in.Dedup = tt.mode
+			out, err := u.Flights(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Flights: %v", err)
+			}
+			if len(out.Flights) != tt.wantFlights {
+				t.Fatalf("expected %d flights, got %d", tt.wantFlights, len(out.Flights))
+			}
+			if got := len(out.Flights[0].Offers); got != tt.wantOffers {
+				t.Fatalf("expected %d offers, got %d", tt.wantOffers, got)
+			}
+		})
+	}
+}
+
+func TestFlightsFacetsSurviveCacheHit(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("GA410", "CGK", "DPS", at(15, 14), 1400000),
+		stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000),
+	}}
+	u := newTestUsecase(t, stub)
+	in := oneWayInput
+	in.Filters = FlightFilters{Airlines: []string{"GA"}}
+	in.Limit = 1
+
+	miss, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	hit, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if miss.Metadata.CacheHit || !hit.Metadata.CacheHit {
+		t.Fatalf("expected a miss then a hit, got %t and %t", miss.Metadata.CacheHit, hit.Metadata.CacheHit)
+	}
+	if calls := stub.calls.Load(); calls != 1 {
+		t.Fatalf("expected one provider call, got %d", calls)
+	}
+	if miss.Metadata.NextCursor == nil {
+		t.Fatal("expected a next cursor on the first page")
+	}
+	page, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: *miss.Metadata.NextCursor})
+	if err != nil {
+		t.Fatalf("FlightsPage: %v", err)
+	}
+
+	for name, output := range map[string]*FlightsOutput{"miss": miss, "hit": hit, "page": page} {
+		facets := output.Facets
+		if facets.Total != 3 || len(facets.Airlines) != 2 || len(facets.DepartureTimes) != 3 || len(facets.PriceHistogram) == 0 {
+			t.Fatalf("%s: expected facets of all 3 flights, got %+v", name, facets)
+		}
+		if facets.PriceRange == nil || facets.PriceRange.Min != 780000 || facets.Duration == nil {
+			t.Fatalf("%s: expected price range and duration, got %+v", name, facets)
+		}
+	}
+}
+
+func TestCloneFlightsOutput(t *testing.T) {
+	cursor := "next"
+	original := &FlightsOutput{
+		Flights:  []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)},
+		Metadata: SearchMetadata{PendingProviders: []string{"Lion Air"}, NextCursor: &cursor, NextReturnCursor: &cursor},
+		Facets:   Facets{Airlines: []FacetBucket{{Value: "GA"}}, PriceRange: &PriceRange{Min: 1}},
+	}
+
+	clone := CloneFlightsOutput(original)
+	clone.Flights[0].ID = "changed"
+	clone.Metadata.PendingProviders[0] = "changed"
+	*clone.Metadata.NextCursor = "changed"
+	*clone.Metadata.NextReturnCursor = "changed"
+	clone.Facets.Airlines[0].Value = "changed"
+	clone.Facets.PriceRange.Min = 2
+
+	if original.Flights[0].ID != "GA400" || original.Metadata.PendingProviders[0] != "Lion Air" || cursor != "next" {
+		t.Fatalf("expected the clone not to share flights, pending providers or cursors, got %+v", original)
+	}
+	if original.Facets.Airlines[0].Value != "GA" || original.Facets.PriceRange.Min != 1 {
+		t.Fatalf("expected the clone not to share facets, got %+v", original.Facets)
+	}
+}
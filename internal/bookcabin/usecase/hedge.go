@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+type HedgeOptions struct {
+	Percentile float64
+	MinSamples int
+	WindowSize int
+}
+
+type latencyTracker struct {
+	mu      sync.Mutex
+	size    int
+	samples map[string][]time.Duration
+	next    map[string]int
+}
+
+func newLatencyTracker(size int) *latencyTracker {
+	return &latencyTracker{
+		size:    size,
+		samples: map[string][]time.Duration{},
+		next:    map[string]int{},
+	}
+}
+
+func (t *latencyTracker) Observe(name string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := t.samples[name]
+	if len(samples) < t.size {
+		t.samples[name] = append(samples, latency)
+		return
+	}
+	samples[t.next[name]] = latency
+	t.next[name] = (t.next[name] + 1) % t.size
+}
+
+func (t *latencyTracker) Percentile(name string, percentile float64, minSamples int) (time.Duration, bool) {
+	t.mu.Lock()
+	samples := slices.Clone(t.samples[name])
+	t.mu.Unlock()
+
+	if len(samples) == 0 || len(samples) < minSamples {
+		return 0, false
+	}
+	slices.Sort(samples)
+	idx := int(math.Ceil(percentile*float64(len(samples)))) - 1
+	idx = min(max(idx, 0), len(samples)-1)
+	return samples[idx], true
+}
+
+func (u *Usecase) searchDeadlineFrom(start time.Time) time.Time {
+	if u.searchDeadline <= 0 {
+		return time.Time{}
+	}
+	return start.Add(u.searchDeadline)
+}
+
+func (u *Usecase) hedgeDelay(name string) (time.Duration, bool) {
+	if u.latencies == nil {
+		return 0, false
+	}
+	return u.latencies.Percentile(name, u.hedge.Percentile, u.hedge.MinSamples)
+}
+
+type attemptResult struct {
+	flights []entity.Flight
+	err     error
+	started time.Time
+}
+
+// searchAttempt runs one provider call. When hedging is enabled and the call
+// outlives the provider's latency percentile, a duplicate request is issued and
+// the first successful response wins.
+func (u *Usecase) searchAttempt(ctx context.Context, p provider.Provider, req provider.SearchRequest) ([]entity.Flight, bool, error) {
+	name := p.Name()
+	delay, ok := u.hedgeDelay(name)
+	if !ok {
+		started := time.Now()
+		flights, err := p.Search(ctx, req)
+		if err == nil && u.latencies != nil {
+			u.latencies.Observe(name, time.Since(started))
+		}
+		return flights, false, err
+	}
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resCh := make(chan attemptResult, 2)
+	launch := func() {
+		started := time.Now()
+		go func() {
+			flights, err := p.Search(attemptCtx, req)
+			resCh <- attemptResult{flights: flights, err: err, started: started}
+		}()
+	}
+
+	launch()
+	inFlight := 1
+	hedged := false
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			hedged = true
+			inFlight++
+			launch()
+		case res := <-resCh:
+			inFlight--
+			if res.err == nil {
+				u.latencies.Observe(name, time.Since(res.started))
+				return res.flights, hedged, nil
+			}
+			if inFlight == 0 {
+				return nil, hedged, res.err
+			}
+		}
+	}
+}
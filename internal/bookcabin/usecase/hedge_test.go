@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+func observeAll(tracker *latencyTracker, name string, millis ...int) {
+	for _, ms := range millis {
+		tracker.Observe(name, time.Duration(ms)*time.Millisecond)
+	}
+}
+
+func TestLatencyTrackerPercentile(t *testing.T) {
+	tracker := newLatencyTracker(100)
+	observeAll(tracker, "Stub", 10, 9, 8, 7, 6, 5, 4, 3, 2, 1)
+
+	tests := []struct {
+		percentile float64
+		minSamples int
+		want       time.Duration
+		wantOK     bool
+	}{
+		{percentile: 0.95, minSamples: 10, want: 10 * time.Millisecond, wantOK: true},
+		{percentile: 0.9, minSamples: 10, want: 9 * time.Millisecond, wantOK: true},
+		{percentile: 0.5, minSamples: 1, want: 5 * time.Millisecond, wantOK: true},
+		{percentile: 0.01, minSamples: 1, want: 1 * time.Millisecond, wantOK: true},
+		{percentile: 1, minSamples: 1, want: 10 * time.Millisecond, wantOK: true},
+		{percentile: 0.95, minSamples: 11, wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := tracker.Percentile("Stub", tt.percentile, tt.minSamples)
+		if ok != tt.wantOK || got != tt.want {
+			t.Fatalf("p%v with %d min samples: expected %v (%t), got %v (%t)", tt.percentile, tt.minSamples, tt.want, tt.wantOK, got, ok)
+		}
+	}
+	if _, ok := tracker.Percentile("Other", 0.5, 0); ok {
+		t.Fatal("expected no percentile for a provider without samples")
+	}
+}
+
+func TestLatencyTrackerWindowWraps(t *testing.T) {
+	tracker := newLatencyTracker(3)
+	observeAll(tracker, "Stub", 1, 2, 3, 4, 5)
+	observeAll(tracker, "Other", 50)
+
+	if got := tracker.samples["Stub"]; !reflect.DeepEqual(got, []time.Duration{4 * time.Millisecond, 5 * time.Millisecond, 3 * time.Millisecond}) {
+		t.Fatalf("expected the two oldest samples to be overwritten, got %v", got)
+	}
+	if got, _ := tracker.Percentile("Stub", 0.01, 3); got != 3*time.Millisecond {
+		t.Fatalf("expected the fastest remaining sample 3ms, got %v", got)
+	}
+	observeAll(tracker, "Stub", 6)
+	if got, _ := tracker.Percentile("Stub", 0.01, 3); got != 4*time.Millisecond {
+		t.Fatalf("expected 3ms to be overwritten next, got %v", got)
+	}
+	if got, _ := tracker.Percentile("Other", 1, 1); got != 50*time.Millisecond {
+		t.Fatalf("expected providers to keep separate windows, got %v", got)
+	}
+}
+
+// newHedgedUsecase hedges p after about 10ms.
+func newHedgedUsecase(t *testing.T, p provider.Provider) *Usecase {
+	t.Helper()
+	dep := testDependency(p)
+	dep.Hedge = &HedgeOptions{Percentile: 0.5, MinSamples: 3, WindowSize: 10}
+	u := newUsecaseFrom(t, dep)
+	observeAll(u.latencies, p.Name(), 10, 10, 10)
+	return u
+}
+
+type attemptOutcome struct {
+	flights []entity.Flight
+	hedged  bool
+	err     error
+}
+
+func TestSearchAttemptFirstSuccessWins(t *testing.T) {
+	firstCanceled := make(chan struct{})
+	flights := []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)}
+	stub := &stubProvider{}
+	stub.search = func(ctx context.Context, _ provider.SearchRequest) ([]entity.Flight, error) {
+		if stub.calls.Load() == 1 {
+			<-ctx.Done()
+			close(firstCanceled)
+			return nil, ctx.Err()
+		}
+		return flights, nil
+	}
+	u := newHedgedUsecase(t, stub)
+
+	got, hedged, err := u.searchAttempt(context.Background(), stub, provider.SearchRequest{})
+	if err != nil || !hedged || len(got) != 1 {
+		t.Fatalf("expected the hedged call to answer, got %v, %t, %v", got, hedged, err)
+	}
+	select {
+	case <-firstCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the slow attempt to be canceled")
+	}
+}
+
+func TestSearchAttemptFailsOnlyAfterEveryAttempt(t *testing.T) {
+	errFirst := errors.New("first attempt failed")
+	errSecond := errors.New("second attempt failed")
+	releaseFirst := make(chan struct{})
+	releaseSecond := make(chan struct{})
+	stub := &stubProvider{}
+	stub.search = func(_ context.Context, _ provider.SearchRequest) ([]entity.Flight, error) {
+		if stub.calls.Load() == 1 {
+			<-releaseFirst
+			return nil, errFirst
+		}
+		<-releaseSecond
+		return nil, errSecond
+	}
+	u := newHedgedUsecase(t, stub)
+
+	done := make(chan attemptOutcome, 1)
+	go func() {
+		flights, hedged, err := u.searchAttempt(context.Background(), stub, provider.SearchRequest{})
+		done <- attemptOutcome{flights: flights, hedged: hedged, err: err}
+	}()
+
+	waitFor(t, func() bool { return stub.calls.Load() == 2 })
+	close(releaseFirst)
+	select {
+	case out := <-done:
+		t.Fatalf("expected to keep waiting for the hedged call, got %+v", out)
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	close(releaseSecond)
+	out := <-done
+	if !errors.Is(out.err, errSecond) || !out.hedged {
+		t.Fatalf("expected the last failure after hedging, got %+v", out)
+	}
+}
+
+func TestSearchAttemptFailureBeforeHedgeDelay(t *testing.T) {
+	errFailed := errors.New("failed fast")
+	stub := &stubProvider{search: func(context.Context, provider.SearchRequest) ([]entity.Flight, error) {
+		return nil, errFailed
+	}}
+	u := newHedgedUsecase(t, stub)
+
+	_, hedged, err := u.searchAttempt(context.Background(), stub, provider.SearchRequest{})
+	if !errors.Is(err, errFailed) || hedged {
+		t.Fatalf("expected an unhedged failure, got %t and %v", hedged, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if calls := stub.calls.Load(); calls != 1 {
+		t.Fatalf("expected no hedge after a fast failure, got %d calls", calls)
+	}
+}
+
+func TestSearchAttemptWithoutEnoughSamples(t *testing.T) {
+	stub := &stubProvider{}
+	u := newHedgedUsecase(t, stub)
+	u.latencies = newLatencyTracker(10)
+
+	if _, hedged, err := u.searchAttempt(context.Background(), stub, provider.SearchRequest{}); err != nil || hedged {
+		t.Fatalf("expected a plain call, got %t and %v", hedged, err)
+	}
+	if got := len(u.latencies.samples[stub.Name()]); got != 1 {
+		t.Fatalf("expected the call latency to be recorded, got %d samples", got)
+	}
+}
+
+func TestFlightsMarksLateProvidersPending(t *testing.T) {
+	fast := &stubProvider{name: "Fast", flights: []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)}}
+	var slowCanceled atomic.Bool
+	slow := &stubProvider{name: "Slow", search: func(ctx context.Context, _ provider.SearchRequest) ([]entity.Flight, error) {
+		<-ctx.Done()
+		slowCanceled.Store(true)
+		return nil, ctx.Err()
+	}}
+	dep := testDependency(fast, slow)
+	dep.SearchDeadline = 50 * time.Millisecond
+	u := newUsecaseFrom(t, dep)
+	in := FlightsInput{Origin: "CGK", Destination: "DPS", DepartureDate: at(15, 0), Passengers: entity.Passengers{Adults: 1}}
+
+	out, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	meta := out.Metadata
+	if !meta.Partial || meta.ProvidersPending != 1 || !reflect.DeepEqual(meta.PendingProviders, []string{"Slow"}) {
+		t.Fatalf("expected Slow to be pending, got %+v", meta)
+	}
+	if meta.ProvidersSucceeded != 1 || len(out.Flights) != 1 {
+		t.Fatalf("expected the fast provider's results, got %+v", meta)
+	}
+	for _, status := range meta.Providers {
+		if status.Name == "Slow" && status.Outcome != ProviderOutcomePending {
+			t.Fatalf("expected a pending status for Slow, got %+v", status)
+		}
+	}
+	waitFor(t, slowCanceled.Load)
+
+	if _, err := u.Flights(context.Background(), in); err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if calls := fast.calls.Load(); calls != 2 {
+		t.Fatalf("expected the partial result not to be cached, got %d calls", calls)
+	}
+}
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,19 +13,57 @@ import (
 
 func buildCacheKey(in FlightsInput) string {
 	return fmt.Sprintf(
-		"%s|%s|%s|%s|%d|%s|%s|%s|%s",
+		"%s~%g|%s~%g|%s|%s|%d-%d-%d|%s|%s|%s|%s|%s|%s|%s",
 		strings.ToUpper(in.Origin),
+		in.OriginRadiusKm,
 		strings.ToUpper(in.Destination),
+		in.DestinationRadiusKm,
 		in.DepartureDate.Format("2006-01-02"),
 		formatOptionalDate(in.ReturnDate),
-		in.Passengers,
+		in.Passengers.Adults,
+		in.Passengers.Children,
+		in.Passengers.Infants,
 		strings.ToLower(in.CabinClass),
 		formatFilters(in.Filters),
-		strings.ToLower(in.Sort.Field),
-		strings.ToLower(in.Sort.Order),
+		formatSort(in.Sort),
+		formatPairing(in.Pairing),
+		strings.ToUpper(in.Currency),
+		strings.ToLower(in.Dedup),
+		formatScoreOptions(in.Scoring),
 	)
 }
 
+func formatSort(keys []SortOption) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key.Field+":"+key.Order)
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatScoreOptions(opts ScoreOptions) string {
+	if opts.Weights == nil {
+		return opts.PreferredTime
+	}
+	names := make([]string, 0, len(opts.Weights))
+	for name := range opts.Weights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		parts = append(parts, name+":"+strconv.FormatFloat(opts.Weights[name], 'f', -1, 64))
+	}
+	return strings.Join(append(parts, opts.PreferredTime), ",")
+}
+
+func formatPairing(value *PairingOption) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d,%d", value.MinTurnaroundMinute, value.Limit)
+}
+
 func formatOptionalDate(value *time.Time) string {
 	if value == nil {
 		return ""
@@ -33,17 +73,26 @@ func formatOptionalDate(value *time.Time) string {
 
 func formatFilters(filters FlightFilters) string {
 	parts := []string{
-		formatOptionalInt(filters.MinPrice),
-		formatOptionalInt(filters.MaxPrice),
+		formatOptionalFloat(filters.MinPrice),
+		formatOptionalFloat(filters.MaxPrice),
+		filters.PriceBasis,
 		formatOptionalInt(filters.Stops),
 		formatOptionalInt(filters.MaxStops),
 		formatOptionalInt(filters.MinDuration),
 		formatOptionalInt(filters.MaxDuration),
-		formatOptionalTime(filters.DepartAfter),
-		formatOptionalTime(filters.DepartBefore),
-		formatOptionalTime(filters.ArriveAfter),
-		formatOptionalTime(filters.ArriveBefore),
-		formatAirlines(filters.Airlines),
+		formatOptionalInt(filters.MinLayover),
+		formatOptionalInt(filters.MaxLayover),
+		formatList(filters.Via),
+		formatTimeBound(filters.DepartAfter),
+		formatTimeBound(filters.DepartBefore),
+		formatTimeBound(filters.ArriveAfter),
+		formatTimeBound(filters.ArriveBefore),
+		formatList(filters.Airlines),
+		formatList(filters.AirlineTypes),
+		formatList(filters.Alliances),
+		formatList(filters.Amenities),
+		filters.CheckedBaggage,
+		formatOptionalInt(filters.MinCheckedKg),
 	}
 	return strings.Join(parts, ",")
 }
@@ -55,14 +104,24 @@ func formatOptionalInt(value *int) string {
 	return fmt.Sprintf("%d", *value)
 }
 
-func formatOptionalTime(value *time.Time) string {
+func formatOptionalFloat(value *float64) string {
 	if value == nil {
 		return ""
 	}
-	return value.Format(time.RFC3339)
+	return strconv.FormatFloat(*value, 'f', -1, 64)
 }
 
-func formatAirlines(values []string) string {
+func formatTimeBound(value *TimeBound) string {
+	if value == nil {
+		return ""
+	}
+	if value.Local {
+		return fmt.Sprintf("%02d:%02d", value.Hour, value.Minute)
+	}
+	return value.Instant.Format(time.RFC3339)
+}
+
+func formatList(values []string) string {
 	if len(values) == 0 {
 		return ""
 	}
@@ -89,8 +148,43 @@ func CloneFlightsOutput(value *FlightsOutput) *FlightsOutput {
 		Metadata:       value.Metadata,
 		Flights:        make([]entity.Flight, len(value.Flights)),
 		ReturnFlights:  make([]entity.Flight, len(value.ReturnFlights)),
+		Itineraries:    make([]entity.Itinerary, len(value.Itineraries)),
 	}
 	copy(clone.Flights, value.Flights)
 	copy(clone.ReturnFlights, value.ReturnFlights)
+	copy(clone.Itineraries, value.Itineraries)
+	clone.Metadata.FailedProviders = slices.Clone(value.Metadata.FailedProviders)
+	clone.Metadata.PendingProviders = slices.Clone(value.Metadata.PendingProviders)
+	clone.Metadata.Providers = slices.Clone(value.Metadata.Providers)
+	if value.Metadata.NextCursor != nil {
+		cursor := *value.Metadata.NextCursor
+		clone.Metadata.NextCursor = &cursor
+	}
+	if value.Metadata.NextReturnCursor != nil {
+		cursor := *value.Metadata.NextReturnCursor
+		clone.Metadata.NextReturnCursor = &cursor
+	}
+	clone.Facets = cloneFacets(value.Facets)
+	if value.ReturnFacets != nil {
+		returnFacets := cloneFacets(*value.ReturnFacets)
+		clone.ReturnFacets = &returnFacets
+	}
+	return clone
+}
+
+func cloneFacets(value Facets) Facets {
+	clone := value
+	clone.Airlines = slices.Clone(value.Airlines)
+	clone.Stops = slices.Clone(value.Stops)
+	clone.DepartureTimes = slices.Clone(value.DepartureTimes)
+	clone.PriceHistogram = slices.Clone(value.PriceHistogram)
+	if value.PriceRange != nil {
+		priceRange := *value.PriceRange
+		clone.PriceRange = &priceRange
+	}
+	if value.Duration != nil {
+		duration := *value.Duration
+		clone.Duration = &duration
+	}
 	return clone
 }
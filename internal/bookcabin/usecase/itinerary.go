@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+type PairingOption struct {
+	MinTurnaroundMinute int
+	Limit               int
+}
+
+func (u *Usecase) buildItineraries(outbound, inbound []entity.Flight, opt PairingOption, in FlightsInput) []entity.Itinerary {
+	itineraries := make([]entity.Itinerary, 0)
+	for _, out := range outbound {
+		for _, ret := range inbound {
+			turnaround := int(ret.Departure.Time.Sub(out.Arrival.Time).Minutes())
+			if turnaround < opt.MinTurnaroundMinute {
+				continue
+			}
+			if !strings.EqualFold(out.Arrival.Airport, ret.Departure.Airport) {
+				continue
+			}
+			itineraries = append(itineraries, entity.Itinerary{
+				ID:               out.ID + "+" + ret.ID,
+				Outbound:         out,
+				Return:           ret,
+				Price:            combinePrices(out.Price, ret.Price),
+				DurationMinute:   out.DurationMinute + ret.DurationMinute,
+				TurnaroundMinute: turnaround,
+			})
+		}
+	}
+
+	u.applyItineraryBestValueScore(itineraries, in.Scoring)
+	sortItineraries(itineraries, in.Sort)
+
+	if opt.Limit > 0 && len(itineraries) > opt.Limit {
+		itineraries = itineraries[:opt.Limit]
+	}
+	return itineraries
+}
+
+func combinePrices(a, b entity.Price) entity.Price {
+	combined := entity.Price{
+		Amount:     a.Amount + b.Amount,
+		Total:      a.Total + b.Total,
+		Passengers: a.Passengers,
+		Currency:   a.Currency,
+		Fares:      combineFares(a.Fares, b.Fares),
+	}
+	if a.Breakdown != nil && b.Breakdown != nil {
+		combined.Breakdown = &entity.PriceBreakdown{
+			BaseFare: a.Breakdown.BaseFare + b.Breakdown.BaseFare,
+			Taxes:    a.Breakdown.Taxes + b.Breakdown.Taxes,
+			Fees:     a.Breakdown.Fees + b.Breakdown.Fees,
+		}
+	}
+	return combined
+}
+
+func combineFares(a, b []entity.PassengerFare) []entity.PassengerFare {
+	combined := make([]entity.PassengerFare, 0, len(a))
+	index := make(map[string]int, len(a))
+	for _, fare := range append(append([]entity.PassengerFare{}, a...), b...) {
+		i, ok := index[fare.Type]
+		if !ok {
+			index[fare.Type] = len(combined)
+			combined = append(combined, fare)
+			continue
+		}
+		combined[i].Amount += fare.Amount
+		combined[i].Total += fare.Total
+	}
+	return combined
+}
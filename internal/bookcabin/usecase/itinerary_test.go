@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func TestBuildItineraries(t *testing.T) {
+	// Both outbound flights land in DPS at 08:00 and 10:00.
+	outbound := []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1000000),
+		stubFlight("JT740", "CGK", "DPS", at(15, 8), 800000),
+	}
+	inbound := []entity.Flight{
+		stubFlight("GA401", "DPS", "CGK", at(15, 9), 900000),
+		stubFlight("GA403", "DPS", "CGK", at(15, 11), 700000),
+		stubFlight("QZ650", "LOP", "CGK", at(15, 12), 500000),
+	}
+
+	tests := []struct {
+		name    string
+		opt     PairingOption
+		wantIDs []string
+		wantMin []int
+	}{
+		{
+			name:    "no minimum turnaround",
+			opt:     PairingOption{},
+			wantIDs: []string{"GA400+GA401", "GA400+GA403", "JT740+GA403"},
+			wantMin: []int{60, 180, 60},
+		},
+		{
+			name:    "minimum turnaround is inclusive",
+			opt:     PairingOption{MinTurnaroundMinute: 60},
+			wantIDs: []string{"GA400+GA401", "GA400+GA403", "JT740+GA403"},
+			wantMin: []int{60, 180, 60},
+		},
+		{
+			name:    "short connections are dropped",
+			opt:     PairingOption{MinTurnaroundMinute: 90},
+			wantIDs: []string{"GA400+GA403"},
+			wantMin: []int{180},
+		},
+		{
+			name:    "limit",
+			opt:     PairingOption{Limit: 2},
+			wantIDs: []string{"GA400+GA401", "GA400+GA403"},
+			wantMin: []int{60, 180},
+		},
+	}
+	u := newTestUsecase(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := u.buildItineraries(outbound, inbound, tt.opt, FlightsInput{Sort: []SortOption{{Field: "departure"}}})
+			ids := make([]string, 0, len(got))
+			turnarounds := make([]int, 0, len(got))
+			for _, it := range got {
+				ids = append(ids, it.ID)
+				turnarounds = append(turnarounds, it.TurnaroundMinute)
+				if it.Return.Departure.Airport != it.Outbound.Arrival.Airport {
+					t.Fatalf("%s returns from %s but arrives in %s", it.ID, it.Return.Departure.Airport, it.Outbound.Arrival.Airport)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, ids)
+			}
+			if !reflect.DeepEqual(turnarounds, tt.wantMin) {
+				t.Fatalf("expected turnarounds %v, got %v", tt.wantMin, turnarounds)
+			}
+		})
+	}
+}
+
+func TestBuildItinerariesTotals(t *testing.T) {
+	out := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1000000)
+	ret := stubFlight("GA401", "DPS", "CGK", at(20, 9), 900000)
+	ret.DurationMinute = 150
+
+	got := newTestUsecase(t).buildItineraries([]entity.Flight{out}, []entity.Flight{ret}, PairingOption{}, FlightsInput{})
+	if len(got) != 1 {
+		t.Fatalf("expected one itinerary, got %d", len(got))
+	}
+	if got[0].Price.Amount != 1900000 || got[0].DurationMinute != 270 {
+		t.Fatalf("expected summed price and duration, got %+v and %d", got[0].Price, got[0].DurationMinute)
+	}
+}
+
+func TestCombinePrices(t *testing.T) {
+	price := func(amount int, breakdown *entity.PriceBreakdown) entity.Price {
+		return entity.Price{Amount: amount, Total: 2 * amount, Passengers: 2, Currency: "IDR", Breakdown: breakdown, Fares: []entity.PassengerFare{}}
+	}
+
+	tests := []struct {
+		name string
+		a, b entity.Price
+		want entity.Price
+	}{
+		{
+			name: "breakdowns are summed",
+			a:    price(1000000, &entity.PriceBreakdown{BaseFare: 850000, Taxes: 100000, Fees: 50000}),
+			b:    price(900000, &entity.PriceBreakdown{BaseFare: 760000, Taxes: 90000, Fees: 50000}),
+			want: price(1900000, &entity.PriceBreakdown{BaseFare: 1610000, Taxes: 190000, Fees: 100000}),
+		},
+		{
+			name: "a missing breakdown drops it",
+			a:    price(1000000, &entity.PriceBreakdown{BaseFare: 850000, Taxes: 100000, Fees: 50000}),
+			b:    price(900000, nil),
+			want: price(1900000, nil),
+		},
+		{
+			name: "no breakdowns",
+			a:    price(1000000, nil),
+			b:    price(900000, nil),
+			want: price(1900000, nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := combinePrices(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCombineFares(t *testing.T) {
+	outbound := []entity.PassengerFare{
+		{Type: entity.PassengerAdult, Count: 2, Amount: 1000000, Total: 2000000},
+		{Type: entity.PassengerInfant, Count: 1, Amount: 100000, Total: 100000},
+	}
+	inbound := []entity.PassengerFare{
+		{Type: entity.PassengerAdult, Count: 2, Amount: 900000, Total: 1800000},
+		{Type: entity.PassengerInfant, Count: 1, Amount: 90000, Total: 90000},
+	}
+	want := []entity.PassengerFare{
+		{Type: entity.PassengerAdult, Count: 2, Amount: 1900000, Total: 3800000},
+		{Type: entity.PassengerInfant, Count: 1, Amount: 190000, Total: 190000},
+	}
+	if got := combineFares(outbound, inbound); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if outbound[0].Amount != 1000000 {
+		t.Fatalf("expected the outbound fares to stay untouched, got %+v", outbound[0])
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+type MultiCityInput struct {
+	Legs       []MultiCityLeg
+	Passengers entity.Passengers
+	CabinClass string
+	Sort       []SortOption
+	Currency   string
+}
+
+type MultiCityLeg struct {
+	Origin        string
+	Destination   string
+	DepartureDate time.Time
+	Filters       FlightFilters
+}
+
+type MultiCityOutput struct {
+	Metadata MultiCityMetadata
+	Legs     []MultiCityLegOutput
+}
+
+type MultiCityLegOutput struct {
+	SearchCriteria SearchCriteria
+	Metadata       SearchMetadata
+	Flights        []entity.Flight
+}
+
+type MultiCityMetadata struct {
+	TotalLegs          int
+	TotalResults       int
+	Complete           bool
+	EmptyLegs          []int
+	PriceRange         *PriceRange
+	ProvidersSucceeded []int
+	SearchTimeMs       int64
+}
+
+type PriceRange struct {
+	Min      int
+	Max      int
+	MinTotal int
+	MaxTotal int
+	Currency string
+}
+
+func (u *Usecase) MultiCity(ctx context.Context, in MultiCityInput) (*MultiCityOutput, error) {
+	start := time.Now()
+
+	legs := make([]MultiCityLegOutput, len(in.Legs))
+	errs := make([]error, len(in.Legs))
+
+	var wg sync.WaitGroup
+	for i, leg := range in.Legs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output, err := u.Flights(ctx, FlightsInput{
+				Origin:        leg.Origin,
+				Destination:   leg.Destination,
+				DepartureDate: leg.DepartureDate,
+				Passengers:    in.Passengers,
+				CabinClass:    in.CabinClass,
+				Filters:       leg.Filters,
+				Sort:          in.Sort,
+				Currency:      in.Currency,
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			legs[i] = MultiCityLegOutput{
+				SearchCriteria: output.SearchCriteria,
+				Metadata:       output.Metadata,
+				Flights:        output.Flights,
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &MultiCityOutput{
+		Metadata: buildMultiCityMetadata(legs, start),
+		Legs:     legs,
+	}, nil
+}
+
+func buildMultiCityMetadata(legs []MultiCityLegOutput, start time.Time) MultiCityMetadata {
+	meta := MultiCityMetadata{
+		TotalLegs:          len(legs),
+		Complete:           true,
+		EmptyLegs:          make([]int, 0),
+		ProvidersSucceeded: make([]int, 0, len(legs)),
+	}
+
+	priceRange := PriceRange{}
+	for i, leg := range legs {
+		meta.TotalResults += len(leg.Flights)
+		meta.ProvidersSucceeded = append(meta.ProvidersSucceeded, leg.Metadata.ProvidersSucceeded)
+
+		legRange, ok := flightPriceRange(leg.Flights)
+		if !ok {
+			meta.Complete = false
+			meta.EmptyLegs = append(meta.EmptyLegs, i)
+			continue
+		}
+		priceRange.Min += legRange.Min
+		priceRange.Max += legRange.Max
+		priceRange.MinTotal += legRange.MinTotal
+		priceRange.MaxTotal += legRange.MaxTotal
+		priceRange.Currency = legRange.Currency
+	}
+
+	if meta.Complete && len(legs) > 0 {
+		meta.PriceRange = &priceRange
+	}
+	meta.SearchTimeMs = time.Since(start).Milliseconds()
+
+	return meta
+}
+
+func flightPriceRange(flights []entity.Flight) (PriceRange, bool) {
+	if len(flights) == 0 {
+		return PriceRange{}, false
+	}
+	first := flights[0].Price
+	rng := PriceRange{Min: first.Amount, Max: first.Amount, MinTotal: first.Total, MaxTotal: first.Total, Currency: first.Currency}
+	for _, f := range flights[1:] {
+		rng.Min = min(rng.Min, f.Price.Amount)
+		rng.Max = max(rng.Max, f.Price.Amount)
+		rng.MinTotal = min(rng.MinTotal, f.Price.Total)
+		rng.MaxTotal = max(rng.MaxTotal, f.Price.Total)
+	}
+	return rng, true
+}
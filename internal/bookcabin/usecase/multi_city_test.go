@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+func multiCityLegs(routes ...[3]string) []MultiCityLeg {
+	legs := make([]MultiCityLeg, 0, len(routes))
+	for i, r := range routes {
+		legs = append(legs, MultiCityLeg{Origin: r[0], Destination: r[1], DepartureDate: at(15+i*3, 0)})
+	}
+	return legs
+}
+
+func TestMultiCitySearchesEveryLeg(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("JT740", "CGK", "DPS", at(15, 19), 780000),
+		stubFlight("ID6520", "DPS", "SUB", at(18, 9), 650000),
+		stubFlight("QZ7510", "SUB", "CGK", at(21, 7), 540000),
+		stubFlight("GA310", "SUB", "CGK", at(21, 12), 990000),
+		stubFlight("GA999", "CGK", "DPS", at(16, 6), 100000),
+	}}
+	u := newTestUsecase(t, stub)
+
+	out, err := u.MultiCity(context.Background(), MultiCityInput{
+		Legs:       multiCityLegs([3]string{"CGK", "DPS"}, [3]string{"DPS", "SUB"}, [3]string{"SUB", "CGK"}),
+		Passengers: entity.Passengers{Adults: 2},
+		CabinClass: "economy",
+		Sort:       []SortOption{{Field: "price", Order: "asc"}},
+	})
+	if err != nil {
+		t.Fatalf("MultiCity: %v", err)
+	}
+	if calls := stub.calls.Load(); calls != 3 {
+		t.Fatalf("expected one provider call per leg, got %d", calls)
+	}
+
+	wantFlights := [][]string{{"JT740", "GA400"}, {"ID6520"}, {"QZ7510", "GA310"}}
+	for i, leg := range out.Legs {
+		ids := make([]string, 0, len(leg.Flights))
+		for _, f := range leg.Flights {
+			ids = append(ids, f.ID)
+		}
+		if !reflect.DeepEqual(ids, wantFlights[i]) {
+			t.Fatalf("leg %d: expected %v, got %v", i, wantFlights[i], ids)
+		}
+		if leg.SearchCriteria.Origin != out.Legs[i].Flights[0].Departure.Airport {
+			t.Fatalf("leg %d: criteria %+v do not match its flights", i, leg.SearchCriteria)
+		}
+	}
+
+	meta := out.Metadata
+	if meta.TotalLegs != 3 || meta.TotalResults != 5 || !meta.Complete || len(meta.EmptyLegs) != 0 {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+	want := PriceRange{Min: 780000 + 650000 + 540000, Max: 1250000 + 650000 + 990000, Currency: "IDR"}
+	want.MinTotal, want.MaxTotal = 2*want.Min, 2*want.Max
+	if meta.PriceRange == nil || *meta.PriceRange != want {
+		t.Fatalf("expected price range %+v, got %+v", want, meta.PriceRange)
+	}
+}
+
+func TestMultiCityProvidersSucceededPerLeg(t *testing.T) {
+	flights := []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("ID6520", "DPS", "SUB", at(18, 9), 650000),
+	}
+	healthy := &stubProvider{name: "Healthy", flights: flights}
+	flaky := &stubProvider{name: "Flaky", search: func(_ context.Context, req provider.SearchRequest) ([]entity.Flight, error) {
+		if req.Origin == "DPS" {
+			return nil, errors.New("upstream down")
+		}
+		return flights, nil
+	}}
+	u := newTestUsecase(t, healthy, flaky)
+
+	out, err := u.MultiCity(context.Background(), MultiCityInput{
+		Legs:       multiCityLegs([3]string{"CGK", "DPS"}, [3]string{"DPS", "SUB"}),
+		Passengers: entity.Passengers{Adults: 1},
+	})
+	if err != nil {
+		t.Fatalf("MultiCity: %v", err)
+	}
+	if got := out.Metadata.ProvidersSucceeded; !reflect.DeepEqual(got, []int{2, 1}) {
+		t.Fatalf("expected providers succeeded [2 1], got %v", got)
+	}
+	if failed := out.Legs[1].Metadata.FailedProviders; len(failed) != 1 || failed[0].Name != "Flaky" {
+		t.Fatalf("expected Flaky to fail the second leg, got %v", failed)
+	}
+}
+
+func TestMultiCityEmptyLeg(t *testing.T) {
+	stub := &stubProvider{flights: []entity.Flight{
+		stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000),
+		stubFlight("QZ7510", "SUB", "CGK", at(21, 7), 540000),
+	}}
+	u := newTestUsecase(t, stub)
+
+	out, err := u.MultiCity(context.Background(), MultiCityInput{
+		Legs:       multiCityLegs([3]string{"CGK", "DPS"}, [3]string{"DPS", "SUB"}, [3]string{"SUB", "CGK"}),
+		Passengers: entity.Passengers{Adults: 1},
+	})
+	if err != nil {
+		t.Fatalf("MultiCity: %v", err)
+	}
+
+	meta := out.Metadata
+	if meta.Complete || meta.PriceRange != nil {
+		t.Fatalf("expected an incomplete itinerary without a price range, got %+v", meta)
+	}
+	if !reflect.DeepEqual(meta.EmptyLegs, []int{1}) {
+		t.Fatalf("expected leg 1 to be reported empty, got %v", meta.EmptyLegs)
+	}
+	if meta.TotalResults != 2 || len(out.Legs[1].Flights) != 0 {
+		t.Fatalf("expected the other legs to keep their results, got %+v", meta)
+	}
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+// snapshotSuffix marks the cache entries that only exist so a partial search
+// can be paged. Normal searches never read them, so late providers are still
+// picked up by the next search.
+const snapshotSuffix = "|snapshot"
+
+type FlightsPageInput struct {
+	Cursor string
+	// Limit overrides the page size carried by the cursor when set.
+	Limit int
+}
+
+// Paged lists. With pairing the itineraries are the result and are paged on
+// their own; otherwise outbound and return flights each get their own cursor,
+// so a short list does not end while the other still has pages.
+const (
+	pageFlights       = "flights"
+	pageReturnFlights = "return"
+	pageItineraries   = "itineraries"
+)
+
+// pageCursor is what an opaque cursor decodes to: the cache entry holding the
+// sorted result set, the list it pages and where the next page starts.
+type pageCursor struct {
+	Key    string `json:"k"`
+	List   string `json:"t"`
+	Offset int    `json:"o"`
+	Limit  int    `json:"l"`
+}
+
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c) //nolint:errchkjson // plain struct of strings and ints
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (pageCursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return pageCursor{}, false
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Key == "" || c.Offset < 0 || c.Limit <= 0 {
+		return pageCursor{}, false
+	}
+	switch c.List {
+	case pageFlights, pageReturnFlights, pageItineraries:
+		return c, true
+	default:
+		return pageCursor{}, false
+	}
+}
+
+// firstPage windows a fresh search. Complete searches are already cached under
+// their search key; anything else gets a snapshot entry so the cursor still
+// resolves.
+func (u *Usecase) firstPage(ctx context.Context, in FlightsInput, output *FlightsOutput) *FlightsOutput {
+	key := buildCacheKey(in)
+	if output.Metadata.Partial || ctx.Err() != nil {
+		key += snapshotSuffix
+		u.cache.Set(key, output, u.cacheTTL)
+	}
+
+	cursor := pageCursor{Key: key, Limit: in.Limit}
+	if in.ReturnDate != nil && in.Pairing != nil {
+		cursor.List = pageItineraries
+		return paginate(output, cursor)
+	}
+	returnFlights := output.ReturnFlights
+	cursor.List = pageFlights
+	output = paginate(output, cursor)
+	cursor.List = pageReturnFlights
+	output.ReturnFlights, output.Metadata.NextReturnCursor = page(returnFlights, cursor)
+	return output
+}
+
+// FlightsPage serves the page a cursor points at from the cache, without
+// querying the providers again.
+func (u *Usecase) FlightsPage(_ context.Context, in FlightsPageInput) (*FlightsOutput, error) {
+	start := time.Now()
+	cursor, ok := decodeCursor(in.Cursor)
+	if !ok {
+		return nil, pkgerror.NewBusiness("invalid cursor", pkgerror.CodeInvalidInput)
+	}
+	if in.Limit > 0 {
+		cursor.Limit = in.Limit
+	}
+
+	output, ok := u.cache.Get(cursor.Key)
+	if !ok {
+		return nil, pkgerror.NewBusiness("cursor expired, repeat the search", pkgerror.CodeNotFound)
+	}
+	output.Metadata.CacheHit = true
+	output.Metadata.SearchTimeMs = time.Since(start).Milliseconds()
+	return paginate(output, cursor), nil
+}
+
+// paginate cuts the cursor's window out of the list it pages and empties the
+// others, which their own cursors page. The totals keep describing the whole
+// result set.
+func paginate(output *FlightsOutput, cursor pageCursor) *FlightsOutput {
+	output.Metadata.Offset = cursor.Offset
+	output.Metadata.Limit = cursor.Limit
+	output.Metadata.NextCursor = nil
+	output.Metadata.NextReturnCursor = nil
+
+	switch cursor.List {
+	case pageItineraries:
+		output.Itineraries, output.Metadata.NextCursor = page(output.Itineraries, cursor)
+		output.Flights = output.Flights[:0]
+		output.ReturnFlights = output.ReturnFlights[:0]
+	case pageReturnFlights:
+		output.ReturnFlights, output.Metadata.NextReturnCursor = page(output.ReturnFlights, cursor)
+		output.Flights = output.Flights[:0]
+		output.Itineraries = output.Itineraries[:0]
+	default:
+		output.Flights, output.Metadata.NextCursor = page(output.Flights, cursor)
+		output.ReturnFlights = output.ReturnFlights[:0]
+		output.Itineraries = output.Itineraries[:0]
+	}
+	return output
+}
+
+// page returns the cursor's window of items and the cursor of the window
+// after it, nil on the last page.
+func page[T any](items []T, cursor pageCursor) ([]T, *string) {
+	var next *string
+	if end := cursor.Offset + cursor.Limit; end < len(items) {
+		value := encodeCursor(pageCursor{Key: cursor.Key, List: cursor.List, Offset: end, Limit: cursor.Limit})
+		next = &value
+	}
+	if cursor.Offset >= len(items) {
+		return items[:0], next
+	}
+	return items[cursor.Offset:min(cursor.Offset+cursor.Limit, len(items))], next
+}
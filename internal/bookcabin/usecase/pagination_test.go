@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := pageCursor{Key: "cgk~0|dps~0", List: pageReturnFlights, Offset: 20, Limit: 10}
+	got, ok := decodeCursor(encodeCursor(want))
+	if !ok || got != want {
+		t.Fatalf("expected %+v, got %+v (valid %v)", want, got, ok)
+	}
+
+	raw := func(value string) string { return base64.RawURLEncoding.EncodeToString([]byte(value)) }
+	invalid := map[string]string{
+		"not base64":      "%%%",
+		"not json":        raw("cursor"),
+		"no key":          raw(`{"t":"flights","o":0,"l":10}`),
+		"negative offset": raw(`{"k":"key","t":"flights","o":-1,"l":10}`),
+		"no limit":        raw(`{"k":"key","t":"flights","o":0,"l":0}`),
+		"unknown list":    raw(`{"k":"key","t":"offers","o":0,"l":10}`),
+	}
+	for name, value := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if c, ok := decodeCursor(value); ok {
+				t.Fatalf("expected an invalid cursor, got %+v", c)
+			}
+		})
+	}
+}
+
+// pagedProvider returns outbound CGK -> DPS flights on the 15th and return
+// DPS -> CGK flights on the 20th.
+func pagedProvider(outbound, inbound int) *stubProvider {
+	flights := make([]entity.Flight, 0, outbound+inbound)
+	for i := range outbound {
+		flights = append(flights, stubFlight(fmt.Sprintf("GA40%d", i), "CGK", "DPS", at(15, 6+i), 1000000+i*1000))
+	}
+	for i := range inbound {
+		flights = append(flights, stubFlight(fmt.Sprintf("GA50%d", i), "DPS", "CGK", at(20, 6+i), 900000+i*1000))
+	}
+	return &stubProvider{flights: flights}
+}
+
+func roundTripInput(limit int) FlightsInput {
+	in := oneWayInput
+	returnDate := at(20, 0)
+	in.ReturnDate = &returnDate
+	in.Sort = []SortOption{{Field: SortPrice}}
+	in.Limit = limit
+	return in
+}
+
+func pageIDs(flights []entity.Flight) string {
+	return fmt.Sprint(flightIDs(flights))
+}
+
+func TestFlightsPagesOutboundAndReturnSeparately(t *testing.T) {
+	stub := pagedProvider(5, 3)
+	u := newTestUsecase(t, stub)
+
+	first, err := u.Flights(context.Background(), roundTripInput(2))
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if got := pageIDs(first.Flights); got != "[GA400 GA401]" {
+		t.Fatalf("expected the first outbound page, got %s", got)
+	}
+	if got := pageIDs(first.ReturnFlights); got != "[GA500 GA501]" {
+		t.Fatalf("expected the first return page, got %s", got)
+	}
+	if first.Metadata.TotalResults != 8 || first.Metadata.NextCursor == nil || first.Metadata.NextReturnCursor == nil {
+		t.Fatalf("expected totals for every page and both cursors, got %+v", first.Metadata)
+	}
+
+	second, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: *first.Metadata.NextCursor})
+	if err != nil {
+		t.Fatalf("FlightsPage: %v", err)
+	}
+	if got := pageIDs(second.Flights); got != "[GA402 GA403]" || len(second.ReturnFlights) != 0 {
+		t.Fatalf("expected only the second outbound page, got %s and %s", got, pageIDs(second.ReturnFlights))
+	}
+	if !second.Metadata.CacheHit || second.Metadata.Offset != 2 || second.Metadata.NextReturnCursor != nil {
+		t.Fatalf("expected a cached outbound page at offset 2, got %+v", second.Metadata)
+	}
+
+	last, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: *second.Metadata.NextCursor, Limit: 5})
+	if err != nil {
+		t.Fatalf("FlightsPage: %v", err)
+	}
+	if got := pageIDs(last.Flights); got != "[GA404]" || last.Metadata.NextCursor != nil {
+		t.Fatalf("expected the last outbound page without a cursor, got %s and %v", got, last.Metadata.NextCursor)
+	}
+
+	returns, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: *first.Metadata.NextReturnCursor})
+	if err != nil {
+		t.Fatalf("FlightsPage: %v", err)
+	}
+	if got := pageIDs(returns.ReturnFlights); got != "[GA502]" || len(returns.Flights) != 0 || returns.Metadata.NextReturnCursor != nil {
+		t.Fatalf("expected only the last return page, got %s and %s", got, pageIDs(returns.Flights))
+	}
+
+	if calls := stub.calls.Load(); calls != 2 {
+		t.Fatalf("expected pages to be served from the cache, got %d provider calls", calls)
+	}
+}
+
+func TestFlightsPagesItinerariesWithPairing(t *testing.T) {
+	u := newTestUsecase(t, pagedProvider(2, 2))
+	in := roundTripInput(3)
+	in.Pairing = &PairingOption{}
+
+	first, err := u.Flights(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Flights: %v", err)
+	}
+	if len(first.Itineraries) != 3 || len(first.Flights) != 0 || len(first.ReturnFlights) != 0 {
+		t.Fatalf("expected a page of 3 itineraries only, got %d itineraries, %d and %d flights", len(first.Itineraries), len(first.Flights), len(first.ReturnFlights))
+	}
+	if first.Metadata.NextCursor == nil || first.Metadata.NextReturnCursor != nil {
+		t.Fatalf("expected the cursor to follow the itineraries, got %+v", first.Metadata)
+	}
+
+	second, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: *first.Metadata.NextCursor})
+	if err != nil {
+		t.Fatalf("FlightsPage: %v", err)
+	}
+	if len(second.Itineraries) != 1 || second.Metadata.NextCursor != nil {
+		t.Fatalf("expected the last itinerary without a cursor, got %d and %v", len(second.Itineraries), second.Metadata.NextCursor)
+	}
+	if second.Itineraries[0].ID == first.Itineraries[0].ID {
+		t.Fatalf("expected the second page to move on, got %s again", second.Itineraries[0].ID)
+	}
+}
+
+func TestFirstPageStoresSnapshot(t *testing.T) {
+	u := newTestUsecase(t)
+	in := roundTripInput(1)
+	key := buildCacheKey(in)
+	output := func(partial bool) *FlightsOutput {
+		return &FlightsOutput{
+			Flights:  []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1000000), stubFlight("GA401", "CGK", "DPS", at(15, 7), 1000000)},
+			Metadata: SearchMetadata{Partial: partial},
+		}
+	}
+
+	page := u.firstPage(context.Background(), in, output(false))
+	if _, ok := u.cache.Get(key + snapshotSuffix); ok {
+		t.Fatalf("expected no snapshot for a complete search")
+	}
+	cursor, _ := decodeCursor(*page.Metadata.NextCursor)
+	if cursor.Key != key || cursor.List != pageFlights {
+		t.Fatalf("expected the cursor to point at the search entry, got %+v", cursor)
+	}
+
+	page = u.firstPage(context.Background(), in, output(true))
+	snapshot, ok := u.cache.Get(key + snapshotSuffix)
+	if !ok || len(snapshot.Flights) != 2 {
+		t.Fatalf("expected the whole partial result set in a snapshot, got %+v", snapshot)
+	}
+	if _, ok := u.cache.Get(key); ok {
+		t.Fatalf("expected a partial search to stay out of the search entry")
+	}
+	cursor, _ = decodeCursor(*page.Metadata.NextCursor)
+	if cursor.Key != key+snapshotSuffix {
+		t.Fatalf("expected the cursor to point at the snapshot, got %+v", cursor)
+	}
+	if len(page.Flights) != 1 {
+		t.Fatalf("expected one flight on the page, got %d", len(page.Flights))
+	}
+}
+
+func TestFlightsPageErrors(t *testing.T) {
+	u := newTestUsecase(t)
+	tests := []struct {
+		name   string
+		cursor string
+		code   pkgerror.Code
+	}{
+		{name: "invalid cursor", cursor: "not-a-cursor", code: pkgerror.CodeInvalidInput},
+		{name: "expired cursor", cursor: encodeCursor(pageCursor{Key: "gone", List: pageFlights, Limit: 10}), code: pkgerror.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.FlightsPage(context.Background(), FlightsPageInput{Cursor: tt.cursor})
+			var gerr *pkgerror.Error
+			if !errors.As(err, &gerr) || gerr.Code() != tt.code {
+				t.Fatalf("expected code %v, got %v", tt.code, err)
+			}
+		})
+	}
+}
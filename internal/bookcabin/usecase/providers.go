@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+const (
+	LegOutbound = "outbound"
+	LegReturn   = "return"
+
+	ProviderOutcomeSuccess = "success"
+	ProviderOutcomeFailed  = "failed"
+	ProviderOutcomePending = "pending"
+
+	ErrorClassTimeout     = "timeout"
+	ErrorClassDecode      = "decode"
+	ErrorClassTemporary   = "temporary"
+	ErrorClassRateLimited = "rate_limited"
+	ErrorClassCircuitOpen = "circuit_open"
+	ErrorClassUnknown     = "unknown"
+)
+
+type ProviderStatus struct {
+	Name       string
+	Leg        string
+	Route      string
+	Outcome    string
+	ErrorClass string
+	Attempts   int
+	Hedged     bool
+	LatencyMs  int64
+	Results    int
+}
+
+type breakerReporter interface {
+	BreakerStatus() provider.BreakerStatus
+}
+
+func (u *Usecase) CircuitBreakers(_ context.Context) []provider.BreakerStatus {
+	statuses := make([]provider.BreakerStatus, 0, len(u.providers))
+	for _, p := range u.providers {
+		if reporter, ok := p.(breakerReporter); ok {
+			statuses = append(statuses, reporter.BreakerStatus())
+		}
+	}
+	return statuses
+}
+
+func newProviderStatus(res providerResult, leg string) ProviderStatus {
+	status := ProviderStatus{
+		Name:      res.name,
+		Leg:       leg,
+		Outcome:   ProviderOutcomeSuccess,
+		Attempts:  res.attempts,
+		Hedged:    res.hedged,
+		LatencyMs: res.latency.Milliseconds(),
+		Results:   len(res.flights),
+	}
+	if res.pending {
+		status.Outcome = ProviderOutcomePending
+		return status
+	}
+	if res.err != nil {
+		status.Outcome = ProviderOutcomeFailed
+		status.ErrorClass = classifyProviderError(res.err)
+	}
+	return status
+}
+
+func hasPendingStatus(statuses []ProviderStatus) bool {
+	for _, status := range statuses {
+		if status.Outcome == ProviderOutcomePending {
+			return true
+		}
+	}
+	return false
+}
+
+// totalProviderResults counts the flights the providers returned before any
+// filtering or deduplication.
+func totalProviderResults(statuses []ProviderStatus) int {
+	total := 0
+	for _, status := range statuses {
+		total += status.Results
+	}
+	return total
+}
+
+func classifyProviderError(err error) string {
+	switch {
+	case errors.Is(err, provider.ErrCircuitOpen):
+		return ErrorClassCircuitOpen
+	case errors.Is(err, provider.ErrRateLimited):
+		return ErrorClassRateLimited
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, provider.ErrDecode):
+		return ErrorClassDecode
+	case errors.Is(err, provider.ErrTemporary):
+		return ErrorClassTemporary
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+func logProviderStatus(ctx context.Context, status ProviderStatus, err error) {
+	attrs := []any{
+		"provider", status.Name,
+		"leg", status.Leg,
+		"route", status.Route,
+		"outcome", status.Outcome,
+		"attempts", status.Attempts,
+		"hedged", status.Hedged,
+		"latency_ms", status.LatencyMs,
+		"results", status.Results,
+	}
+	if status.Outcome == ProviderOutcomePending {
+		slog.WarnContext(ctx, "provider search missed the deadline", attrs...)
+		return
+	}
+	if err == nil {
+		slog.InfoContext(ctx, "provider search completed", attrs...)
+		return
+	}
+	attrs = append(attrs, "error_class", status.ErrorClass, "error", err)
+	slog.WarnContext(ctx, "provider search failed", attrs...)
+}
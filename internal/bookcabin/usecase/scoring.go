@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+const (
+	FactorPrice     = "price"
+	FactorDuration  = "duration"
+	FactorStops     = "stops"
+	FactorBaggage   = "baggage"
+	FactorSeats     = "seats"
+	FactorTimeOfDay = "time_of_day"
+
+	TimeOfDayMorning   = "morning"
+	TimeOfDayAfternoon = "afternoon"
+	TimeOfDayEvening   = "evening"
+	TimeOfDayNight     = "night"
+)
+
+var ErrInvalidScoreWeight = errors.New("invalid score weight")
+
+// scarceSeats is the seat count below which a flight starts losing points
+// for being close to selling out.
+const scarceSeats = 10
+
+// ScoreWeights maps a scoring factor to its relative weight. Weights do not
+// need to add up to one; they are normalized over the factors that apply.
+type ScoreWeights map[string]float64
+
+// DefaultScoreWeights is used when the configuration sets no weights. It keeps
+// the original best value score: 60% price and 40% duration.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		FactorPrice:    0.6,
+		FactorDuration: 0.4,
+	}
+}
+
+// IsScoreFactor reports whether name is a known scoring factor.
+func IsScoreFactor(name string) bool {
+	switch name {
+	case FactorPrice, FactorDuration, FactorStops, FactorBaggage, FactorSeats, FactorTimeOfDay:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTimeOfDay reports whether value is a supported departure time preference.
+func IsTimeOfDay(value string) bool {
+	switch value {
+	case TimeOfDayMorning, TimeOfDayAfternoon, TimeOfDayEvening, TimeOfDayNight:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScoreOptions tunes the best value score of one search. Weights override the
+// configured weight of the factors they name and leave the others as they are;
+// the time of day factor only applies when PreferredTime is set.
+type ScoreOptions struct {
+	Weights       ScoreWeights
+	PreferredTime string
+}
+
+// ScoreCandidate is what a scoring strategy sees of a flight or itinerary.
+type ScoreCandidate struct {
+	Price          int
+	DurationMinute int
+	Stops          int
+	CheckedBaggage bool
+	AvailableSeats int
+	Departure      time.Time
+}
+
+type Score struct {
+	Value   float64
+	Factors []entity.ScoreFactor
+}
+
+// ScoringStrategy rates the candidates of one result set against each other.
+// Lower scores are better.
+type ScoringStrategy interface {
+	Score(candidates []ScoreCandidate, opts ScoreOptions) []Score
+}
+
+// WeightedScoring normalizes every factor to 0 (best) .. 1 (worst) within the
+// result set and adds them up by weight.
+type WeightedScoring struct{}
+
+func (WeightedScoring) Score(candidates []ScoreCandidate, opts ScoreOptions) []Score {
+	scores := make([]Score, len(candidates))
+	if len(candidates) == 0 {
+		return scores
+	}
+
+	factors := []struct {
+		name  string
+		value func(c ScoreCandidate) float64
+	}{
+		{FactorPrice, rangeFactor(candidates, func(c ScoreCandidate) float64 { return float64(c.Price) })},
+		{FactorDuration, rangeFactor(candidates, func(c ScoreCandidate) float64 { return float64(c.DurationMinute) })},
+		{FactorStops, rangeFactor(candidates, func(c ScoreCandidate) float64 { return float64(c.Stops) })},
+		{FactorBaggage, func(c ScoreCandidate) float64 {
+			if c.CheckedBaggage {
+				return 0
+			}
+			return 1
+		}},
+		{FactorSeats, func(c ScoreCandidate) float64 {
+			return float64(scarceSeats-min(max(c.AvailableSeats, 0), scarceSeats)) / scarceSeats
+		}},
+		{FactorTimeOfDay, func(c ScoreCandidate) float64 { return timeOfDayDistance(c.Departure, opts.PreferredTime) }},
+	}
+
+	type activeFactor struct {
+		name   string
+		weight float64
+		value  func(c ScoreCandidate) float64
+	}
+	active := make([]activeFactor, 0, len(factors))
+	total := 0.0
+	for _, f := range factors {
+		weight := max(opts.Weights[f.name], 0)
+		if weight == 0 || (f.name == FactorTimeOfDay && opts.PreferredTime == "") {
+			continue
+		}
+		active = append(active, activeFactor{name: f.name, weight: weight, value: f.value})
+		total += weight
+	}
+
+	for i, c := range candidates {
+		scores[i].Factors = make([]entity.ScoreFactor, 0, len(active))
+		for _, f := range active {
+			factor := entity.ScoreFactor{Name: f.name, Weight: f.weight / total, Score: f.value(c)}
+			scores[i].Value += factor.Weight * factor.Score
+			scores[i].Factors = append(scores[i].Factors, factor)
+		}
+	}
+	return scores
+}
+
+// rangeFactor scales a value linearly between the lowest (0) and highest (1)
+// value in the result set.
+func rangeFactor(candidates []ScoreCandidate, value func(ScoreCandidate) float64) func(ScoreCandidate) float64 {
+	lowest, highest := value(candidates[0]), value(candidates[0])
+	for _, c := range candidates[1:] {
+		lowest = math.Min(lowest, value(c))
+		highest = math.Max(highest, value(c))
+	}
+	spread := highest - lowest
+	if spread == 0 {
+		spread = 1
+	}
+	return func(c ScoreCandidate) float64 {
+		return (value(c) - lowest) / spread
+	}
+}
+
+// timeOfDayDistance is 0 inside the preferred window and grows to 1 twelve
+// hours away from it. Departure times are in the local time of the airport.
+func timeOfDayDistance(departure time.Time, preferred string) float64 {
+	start, end, ok := timeOfDayWindow(preferred)
+	if !ok || inTimeOfDay(departure, preferred) {
+		return 0
+	}
+
+	minute := departure.Hour()*60 + departure.Minute()
+	distance := float64(min(circularDistance(minute, start), circularDistance(minute, end)))
+	return math.Min(distance/(12*60), 1)
+}
+
+// timeOfDayWindow returns the minutes of the day a time of day covers. The
+// night window runs past midnight, so its end is above 24 hours.
+func timeOfDayWindow(name string) (int, int, bool) {
+	switch name {
+	case TimeOfDayMorning:
+		return 5 * 60, 12 * 60, true
+	case TimeOfDayAfternoon:
+		return 12 * 60, 17 * 60, true
+	case TimeOfDayEvening:
+		return 17 * 60, 21 * 60, true
+	case TimeOfDayNight:
+		return 21 * 60, 29 * 60, true
+	default:
+		return 0, 0, false
+	}
+}
+
+func inTimeOfDay(t time.Time, name string) bool {
+	start, end, ok := timeOfDayWindow(name)
+	if !ok {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if minute < start && minute+24*60 < end {
+		minute += 24 * 60
+	}
+	return minute >= start && minute < end
+}
+
+// timeOfDayOf buckets a local time into morning, afternoon, evening, or night.
+func timeOfDayOf(t time.Time) string {
+	for _, name := range []string{TimeOfDayMorning, TimeOfDayAfternoon, TimeOfDayEvening} {
+		if inTimeOfDay(t, name) {
+			return name
+		}
+	}
+	return TimeOfDayNight
+}
+
+func circularDistance(a, b int) int {
+	d := (a - b) % (24 * 60)
+	if d < 0 {
+		d = -d
+	}
+	return min(d, 24*60-d)
+}
+
+func flightCandidate(f entity.Flight) ScoreCandidate {
+	return ScoreCandidate{
+		Price:          f.Price.Amount,
+		DurationMinute: f.DurationMinute,
+		Stops:          f.Stops,
+		CheckedBaggage: f.Baggage.Checked.Included,
+		AvailableSeats: f.AvailableSeats,
+		Departure:      f.Departure.Time,
+	}
+}
+
+func itineraryCandidate(it entity.Itinerary) ScoreCandidate {
+	return ScoreCandidate{
+		Price:          it.Price.Amount,
+		DurationMinute: it.DurationMinute,
+		Stops:          it.Outbound.Stops + it.Return.Stops,
+		CheckedBaggage: it.Outbound.Baggage.Checked.Included && it.Return.Baggage.Checked.Included,
+		AvailableSeats: min(it.Outbound.AvailableSeats, it.Return.AvailableSeats),
+		Departure:      it.Outbound.Departure.Time,
+	}
+}
+
+// scoreOptions lays the request weights over a copy of the configured ones, so
+// a search can retune one factor without dropping the rest.
+func (u *Usecase) scoreOptions(opts ScoreOptions) ScoreOptions {
+	weights := make(ScoreWeights, len(u.scoreWeights)+len(opts.Weights))
+	maps.Copy(weights, u.scoreWeights)
+	maps.Copy(weights, opts.Weights)
+	opts.Weights = weights
+	return opts
+}
+
+func (u *Usecase) applyBestValueScore(flights []entity.Flight, opts ScoreOptions) {
+	candidates := make([]ScoreCandidate, len(flights))
+	for i := range flights {
+		candidates[i] = flightCandidate(flights[i])
+	}
+	for i, score := range u.scoring.Score(candidates, u.scoreOptions(opts)) {
+		flights[i].BestValueScore = score.Value
+		flights[i].ScoreFactors = score.Factors
+	}
+}
+
+func (u *Usecase) applyItineraryBestValueScore(itineraries []entity.Itinerary, opts ScoreOptions) {
+	candidates := make([]ScoreCandidate, len(itineraries))
+	for i := range itineraries {
+		candidates[i] = itineraryCandidate(itineraries[i])
+	}
+	for i, score := range u.scoring.Score(candidates, u.scoreOptions(opts)) {
+		itineraries[i].BestValueScore = score.Value
+		itineraries[i].ScoreFactors = score.Factors
+	}
+}
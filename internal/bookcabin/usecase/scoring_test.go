@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestScoreOptionsMergesWeights(t *testing.T) {
+	dep := testDependency()
+	dep.ScoreWeights = ScoreWeights{FactorPrice: 0.5, FactorDuration: 0.3, FactorStops: 0.2}
+	u := newUsecaseFrom(t, dep)
+
+	tests := []struct {
+		name    string
+		weights ScoreWeights
+		want    ScoreWeights
+	}{
+		{name: "no request weights", want: ScoreWeights{FactorPrice: 0.5, FactorDuration: 0.3, FactorStops: 0.2}},
+		{name: "one factor retuned", weights: ScoreWeights{FactorStops: 0.6}, want: ScoreWeights{FactorPrice: 0.5, FactorDuration: 0.3, FactorStops: 0.6}},
+		{name: "new factor added", weights: ScoreWeights{FactorSeats: 0.1}, want: ScoreWeights{FactorPrice: 0.5, FactorDuration: 0.3, FactorStops: 0.2, FactorSeats: 0.1}},
+		{name: "zero turns a factor off", weights: ScoreWeights{FactorPrice: 0}, want: ScoreWeights{FactorPrice: 0, FactorDuration: 0.3, FactorStops: 0.2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := u.scoreOptions(ScoreOptions{Weights: tt.weights})
+			if !reflect.DeepEqual(got.Weights, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got.Weights)
+			}
+		})
+	}
+	if !reflect.DeepEqual(u.scoreWeights, ScoreWeights{FactorPrice: 0.5, FactorDuration: 0.3, FactorStops: 0.2}) {
+		t.Fatalf("expected the configured weights to stay untouched, got %v", u.scoreWeights)
+	}
+}
+
+func TestDefaultScoreWeights(t *testing.T) {
+	u := newTestUsecase(t)
+	if want := (ScoreWeights{FactorPrice: 0.6, FactorDuration: 0.4}); !reflect.DeepEqual(u.scoreWeights, want) {
+		t.Fatalf("expected default weights %v, got %v", want, u.scoreWeights)
+	}
+}
+
+func TestWeightedScoring(t *testing.T) {
+	morning := time.Date(2025, 12, 15, 6, 0, 0, 0, time.UTC)
+	evening := time.Date(2025, 12, 15, 19, 0, 0, 0, time.UTC)
+	candidates := []ScoreCandidate{
+		{Price: 1000000, DurationMinute: 120, Stops: 0, CheckedBaggage: true, AvailableSeats: 20, Departure: morning},
+		{Price: 500000, DurationMinute: 240, Stops: 2, CheckedBaggage: false, AvailableSeats: 5, Departure: evening},
+		{Price: 750000, DurationMinute: 180, Stops: 1, CheckedBaggage: true, AvailableSeats: 0, Departure: evening},
+	}
+
+	tests := []struct {
+		name        string
+		opts        ScoreOptions
+		wantValues  []float64
+		wantFactors []string
+	}{
+		{
+			name:        "price and duration",
+			opts:        ScoreOptions{Weights: ScoreWeights{FactorPrice: 0.6, FactorDuration: 0.4}},
+			wantValues:  []float64{0.6, 0.4, 0.5},
+			wantFactors: []string{FactorPrice, FactorDuration},
+		},
+		{
+			name:        "weights are normalized",
+			opts:        ScoreOptions{Weights: ScoreWeights{FactorPrice: 3, FactorDuration: 2}},
+			wantValues:  []float64{0.6, 0.4, 0.5},
+			wantFactors: []string{FactorPrice, FactorDuration},
+		},
+		{
+			name:        "stops baggage and seats",
+			opts:        ScoreOptions{Weights: ScoreWeights{FactorStops: 1, FactorBaggage: 1, FactorSeats: 2}},
+			wantValues:  []float64{0, (1 + 1 + 2*0.5) / 4, (0.5 + 0 + 2*1) / 4},
+			wantFactors: []string{FactorStops, FactorBaggage, FactorSeats},
+		},
+		{
+			name:        "time of day without a preference does not count",
+			opts:        ScoreOptions{Weights: ScoreWeights{FactorPrice: 1, FactorTimeOfDay: 1}},
+			wantValues:  []float64{1, 0, 0.5},
+			wantFactors: []string{FactorPrice},
+		},
+		{
+			name:        "time of day with a preference",
+			opts:        ScoreOptions{Weights: ScoreWeights{FactorTimeOfDay: 1}, PreferredTime: TimeOfDayMorning},
+			wantValues:  []float64{0, 7.0 / 12, 7.0 / 12},
+			wantFactors: []string{FactorTimeOfDay},
+		},
+		{
+			name:       "no weights",
+			opts:       ScoreOptions{Weights: ScoreWeights{FactorPrice: 0}},
+			wantValues: []float64{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := WeightedScoring{}.Score(candidates, tt.opts)
+			for i, score := range scores {
+				if !approxEqual(score.Value, tt.wantValues[i]) {
+					t.Fatalf("candidate %d: expected score %v, got %v", i, tt.wantValues[i], score.Value)
+				}
+				names := make([]string, 0, len(score.Factors))
+				weights, contributions := 0.0, 0.0
+				for _, f := range score.Factors {
+					names = append(names, f.Name)
+					weights += f.Weight
+					contributions += f.Weight * f.Score
+					if f.Score < 0 || f.Score > 1 {
+						t.Fatalf("candidate %d: expected %s normalized to 0..1, got %v", i, f.Name, f.Score)
+					}
+				}
+				if len(tt.wantFactors) > 0 && !reflect.DeepEqual(names, tt.wantFactors) {
+					t.Fatalf("candidate %d: expected factors %v, got %v", i, tt.wantFactors, names)
+				}
+				if len(score.Factors) > 0 && !approxEqual(weights, 1) {
+					t.Fatalf("candidate %d: expected weights to add up to 1, got %v", i, weights)
+				}
+				if !approxEqual(contributions, score.Value) {
+					t.Fatalf("candidate %d: expected the breakdown to add up to %v, got %v", i, score.Value, contributions)
+				}
+			}
+		})
+	}
+
+	if scores := (WeightedScoring{}).Score(nil, ScoreOptions{Weights: DefaultScoreWeights()}); len(scores) != 0 {
+		t.Fatalf("expected no scores for no candidates, got %+v", scores)
+	}
+}
+
+func TestWeightedScoringSameValues(t *testing.T) {
+	candidates := []ScoreCandidate{{Price: 500000, DurationMinute: 90}, {Price: 500000, DurationMinute: 90}}
+	for _, score := range (WeightedScoring{}).Score(candidates, ScoreOptions{Weights: DefaultScoreWeights()}) {
+		if score.Value != 0 {
+			t.Fatalf("expected equal candidates to score 0, got %v", score.Value)
+		}
+	}
+}
+
+func TestTimeOfDayDistance(t *testing.T) {
+	tests := []struct {
+		preferred string
+		hour      int
+		want      float64
+	}{
+		{preferred: TimeOfDayMorning, hour: 5, want: 0},
+		{preferred: TimeOfDayMorning, hour: 11, want: 0},
+		{preferred: TimeOfDayMorning, hour: 12, want: 0},
+		{preferred: TimeOfDayMorning, hour: 14, want: 2.0 / 12},
+		{preferred: TimeOfDayMorning, hour: 2, want: 3.0 / 12},
+		{preferred: TimeOfDayNight, hour: 23, want: 0},
+		{preferred: TimeOfDayNight, hour: 3, want: 0},
+		{preferred: TimeOfDayNight, hour: 15, want: 6.0 / 12},
+		{preferred: TimeOfDayAfternoon, hour: 0, want: 7.0 / 12},
+		{preferred: "", hour: 3, want: 0},
+	}
+	for _, tt := range tests {
+		got := timeOfDayDistance(time.Date(2025, 12, 15, tt.hour, 0, 0, 0, time.UTC), tt.preferred)
+		if !approxEqual(got, tt.want) {
+			t.Fatalf("%s at %02d:00: expected %v, got %v", tt.preferred, tt.hour, tt.want, got)
+		}
+	}
+}
+
+func TestFlightsBestValueWeights(t *testing.T) {
+	cheapSlow := stubFlight("JT740", "CGK", "DPS", at(15, 19), 500000)
+	cheapSlow.Arrival.Time = cheapSlow.Departure.Time.Add(5 * time.Hour)
+	cheapSlow.DurationMinute = 300
+	cheapSlow.Stops = 1
+	pricyFast := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1000000)
+	pricyFast.Arrival.Time = pricyFast.Departure.Time.Add(100 * time.Minute)
+	pricyFast.DurationMinute = 100
+	u := newTestUsecase(t, &stubProvider{flights: []entity.Flight{cheapSlow, pricyFast}})
+
+	tests := []struct {
+		name    string
+		scoring ScoreOptions
+		wantIDs []string
+	}{
+		{name: "default weights favor price", wantIDs: []string{"JT740", "GA400"}},
+		{name: "duration outweighs price", scoring: ScoreOptions{Weights: ScoreWeights{FactorDuration: 1}}, wantIDs: []string{"GA400", "JT740"}},
+		{name: "price turned off", scoring: ScoreOptions{Weights: ScoreWeights{FactorPrice: 0}}, wantIDs: []string{"GA400", "JT740"}},
+		// Stops alone would favor GA400; price and duration keep counting.
+		{name: "stops added to the defaults", scoring: ScoreOptions{Weights: ScoreWeights{FactorStops: 0.1}}, wantIDs: []string{"JT740", "GA400"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := oneWayInput
+			in.Scoring = tt.scoring
+			in.Sort = []SortOption{{Field: "best_value"}}
+			out, err := u.Flights(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Flights: %v", err)
+			}
+			ids := make([]string, 0, len(out.Flights))
+			for _, f := range out.Flights {
+				ids = append(ids, f.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkguid"
+)
+
+type SearchJobStatus string
+
+const (
+	SearchJobQueued    SearchJobStatus = "queued"
+	SearchJobRunning   SearchJobStatus = "running"
+	SearchJobCompleted SearchJobStatus = "completed"
+	SearchJobFailed    SearchJobStatus = "failed"
+	SearchJobCanceled  SearchJobStatus = "canceled"
+)
+
+type SearchJobOptions struct {
+	Workers   int
+	QueueSize int
+	TTL       time.Duration
+}
+
+type SearchJob struct {
+	ID            string
+	Status        SearchJobStatus
+	Err           error
+	Providers     []ProviderStatus
+	Flights       []entity.Flight
+	ReturnFlights []entity.Flight
+	Output        *FlightsOutput
+	CreatedAt     time.Time
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+	ExpiresAt     *time.Time
+}
+
+type searchJob struct {
+	state  SearchJob
+	input  FlightsInput
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type searchJobs struct {
+	mu     sync.Mutex
+	jobs   map[string]*searchJob
+	queue  chan *searchJob
+	closed bool
+	wg     sync.WaitGroup
+	ttl    time.Duration
+	ids    pkguid.StringID
+}
+
+func newSearchJobs(opts SearchJobOptions, ids pkguid.StringID) *searchJobs {
+	return &searchJobs{
+		jobs:  map[string]*searchJob{},
+		queue: make(chan *searchJob, max(opts.QueueSize, 1)),
+		ttl:   opts.TTL,
+		ids:   ids,
+	}
+}
+
+// CreateSearch queues a flight search for the worker pool. The job keeps the
+// request context values (such as the correlation ID) but not its
+// cancellation, so it outlives the HTTP request that created it.
+func (u *Usecase) CreateSearch(ctx context.Context, in FlightsInput) (*SearchJob, error) {
+	in.Currency = u.resolveCurrency(in.Currency)
+	if err := u.validateCurrency(ctx, in.Currency); err != nil {
+		return nil, err
+	}
+
+	jobs := u.jobs
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	if jobs.closed {
+		return nil, pkgerror.NewBusiness("search service is shutting down", pkgerror.CodeUnavailable)
+	}
+	jobs.sweep(time.Now())
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job := &searchJob{
+		state: SearchJob{
+			ID:        jobs.ids.Generate(),
+			Status:    SearchJobQueued,
+			CreatedAt: time.Now(),
+		},
+		input:  in,
+		ctx:    jobCtx,
+		cancel: cancel,
+	}
+
+	select {
+	case jobs.queue <- job:
+	default:
+		cancel()
+		return nil, pkgerror.NewBusiness("too many pending searches, try again later", pkgerror.CodeUnavailable)
+	}
+	jobs.jobs[job.state.ID] = job
+
+	return job.snapshot(), nil
+}
+
+func (u *Usecase) SearchJob(_ context.Context, id string) (*SearchJob, error) {
+	jobs := u.jobs
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	jobs.sweep(time.Now())
+	job, ok := jobs.jobs[id]
+	if !ok {
+		return nil, pkgerror.NewBusiness("search not found", pkgerror.CodeNotFound)
+	}
+	return job.snapshot(), nil
+}
+
+// CancelSearch stops a queued or running search. Results collected so far are
+// kept; canceling a finished search is a no-op.
+func (u *Usecase) CancelSearch(_ context.Context, id string) (*SearchJob, error) {
+	jobs := u.jobs
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	jobs.sweep(time.Now())
+	job, ok := jobs.jobs[id]
+	if !ok {
+		return nil, pkgerror.NewBusiness("search not found", pkgerror.CodeNotFound)
+	}
+	if job.state.Status == SearchJobQueued || job.state.Status == SearchJobRunning {
+		jobs.finish(job, SearchJobCanceled, time.Now())
+		job.cancel()
+	}
+	return job.snapshot(), nil
+}
+
+// Close stops accepting searches, cancels the queued ones and waits for the
+// running ones to finish. When ctx expires first, the running searches are
+// canceled as well.
+func (u *Usecase) Close(ctx context.Context) error {
+	jobs := u.jobs
+	jobs.mu.Lock()
+	if !jobs.closed {
+		jobs.closed = true
+		close(jobs.queue)
+		now := time.Now()
+		for _, job := range jobs.jobs {
+			if job.state.Status == SearchJobQueued {
+				jobs.finish(job, SearchJobCanceled, now)
+				job.cancel()
+			}
+		}
+	}
+	jobs.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		jobs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		jobs.mu.Lock()
+		for _, job := range jobs.jobs {
+			job.cancel()
+		}
+		jobs.mu.Unlock()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (u *Usecase) startSearchWorkers(workers int) {
+	for range workers {
+		u.jobs.wg.Add(1)
+		go func() {
+			defer u.jobs.wg.Done()
+			for job := range u.jobs.queue {
+				u.runSearchJob(job)
+			}
+		}()
+	}
+}
+
+func (u *Usecase) runSearchJob(job *searchJob) {
+	jobs := u.jobs
+	jobs.mu.Lock()
+	if job.state.Status != SearchJobQueued {
+		jobs.mu.Unlock()
+		return
+	}
+	started := time.Now()
+	job.state.Status = SearchJobRunning
+	job.state.StartedAt = &started
+	jobs.mu.Unlock()
+
+	output, err := u.FlightsStream(job.ctx, job.input, func(event FlightsStreamEvent) {
+		jobs.record(job, event)
+	})
+
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	defer job.cancel()
+
+	if job.state.Status != SearchJobRunning {
+		return
+	}
+	switch {
+	case err != nil:
+		job.state.Err = err
+		jobs.finish(job, SearchJobFailed, time.Now())
+	case job.ctx.Err() != nil:
+		jobs.finish(job, SearchJobCanceled, time.Now())
+	default:
+		job.state.Output = output
+		job.state.Flights = output.Flights
+		job.state.ReturnFlights = output.ReturnFlights
+		job.state.Providers = output.Metadata.Providers
+		jobs.finish(job, SearchJobCompleted, time.Now())
+	}
+
+	slog.InfoContext(job.ctx, "search job finished",
+		"id", job.state.ID,
+		"status", job.state.Status,
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
+}
+
+// record merges a streamed provider batch into the job, so pollers see the
+// results as they accumulate.
+func (j *searchJobs) record(job *searchJob, event FlightsStreamEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if job.state.Status != SearchJobRunning {
+		return
+	}
+	if event.Provider != nil {
+		job.state.Providers = append(job.state.Providers, *event.Provider)
+	}
+
+	target := &job.state.Flights
+	if event.Leg == LegReturn {
+		target = &job.state.ReturnFlights
+	}
+	if len(event.ReplacedIDs) > 0 {
+		*target = slices.DeleteFunc(*target, func(flight entity.Flight) bool {
+			return slices.Contains(event.ReplacedIDs, flight.ID)
+		})
+	}
+	*target = append(*target, event.Flights...)
+}
+
+func (j *searchJobs) finish(job *searchJob, status SearchJobStatus, now time.Time) {
+	expiresAt := now.Add(j.ttl)
+	job.state.Status = status
+	job.state.FinishedAt = &now
+	job.state.ExpiresAt = &expiresAt
+}
+
+// sweep drops finished jobs past their expiry. Queued and running jobs never
+// expire.
+func (j *searchJobs) sweep(now time.Time) {
+	for id, job := range j.jobs {
+		if job.state.ExpiresAt != nil && now.After(*job.state.ExpiresAt) {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+func (job *searchJob) snapshot() *SearchJob {
+	state := job.state
+	state.Providers = slices.Clone(state.Providers)
+	state.Flights = slices.Clone(state.Flights)
+	state.ReturnFlights = slices.Clone(state.ReturnFlights)
+	state.Output = CloneFlightsOutput(state.Output)
+	return &state
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+// blockingProvider answers once release is closed, or fails when its context
+// ends first.
+func blockingProvider(name string, release <-chan struct{}, flights ...entity.Flight) *stubProvider {
+	return &stubProvider{name: name, search: func(ctx context.Context, _ provider.SearchRequest) ([]entity.Flight, error) {
+		select {
+		case <-release:
+			return flights, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}
+}
+
+func waitForJob(t *testing.T, u *Usecase, id string, cond func(job *SearchJob) bool) *SearchJob {
+	t.Helper()
+	var job *SearchJob
+	waitFor(t, func() bool {
+		var err error
+		job, err = u.SearchJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("SearchJob: %v", err)
+		}
+		return cond(job)
+	})
+	return job
+}
+
+func hasStatus(status SearchJobStatus) func(job *SearchJob) bool {
+	return func(job *SearchJob) bool { return job.Status == status }
+}
+
+func TestSearchJobLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	u := newTestUsecase(t, blockingProvider("Stub", release, stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)))
+
+	job, err := u.CreateSearch(context.Background(), oneWayInput)
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+	if job.Status != SearchJobQueued || job.StartedAt != nil {
+		t.Fatalf("expected a queued job, got %+v", job)
+	}
+
+	running := waitForJob(t, u, job.ID, hasStatus(SearchJobRunning))
+	if running.StartedAt == nil || running.FinishedAt != nil {
+		t.Fatalf("expected a started, unfinished job, got %+v", running)
+	}
+
+	close(release)
+	done := waitForJob(t, u, job.ID, hasStatus(SearchJobCompleted))
+	if done.Output == nil || len(done.Flights) != 1 || len(done.Providers) != 1 {
+		t.Fatalf("expected the search output, got %+v", done)
+	}
+	if done.FinishedAt == nil || done.ExpiresAt == nil || done.Err != nil {
+		t.Fatalf("expected a finished job with an expiry, got %+v", done)
+	}
+}
+
+func TestCancelSearchKeepsPartialResults(t *testing.T) {
+	fast := &stubProvider{name: "Fast", flights: []entity.Flight{stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)}}
+	slow := blockingProvider("Slow", make(chan struct{}))
+	u := newTestUsecase(t, fast, slow)
+
+	job, err := u.CreateSearch(context.Background(), oneWayInput)
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+	waitForJob(t, u, job.ID, func(job *SearchJob) bool { return len(job.Flights) == 1 })
+
+	canceled, err := u.CancelSearch(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("CancelSearch: %v", err)
+	}
+	if canceled.Status != SearchJobCanceled || len(canceled.Flights) != 1 || canceled.Flights[0].ID != "GA400" {
+		t.Fatalf("expected a canceled job with the fast results, got %+v", canceled)
+	}
+
+	// The worker must not overwrite the canceled job once the search unwinds.
+	time.Sleep(20 * time.Millisecond)
+	after, err := u.SearchJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("SearchJob: %v", err)
+	}
+	if after.Status != SearchJobCanceled || len(after.Flights) != 1 || after.Output != nil {
+		t.Fatalf("expected the canceled job to stay as it was, got %+v", after)
+	}
+}
+
+func TestCreateSearchQueueFull(t *testing.T) {
+	dep := testDependency(blockingProvider("Stub", make(chan struct{})))
+	dep.SearchJobs = SearchJobOptions{Workers: 1, QueueSize: 1, TTL: time.Minute}
+	u := newUsecaseFrom(t, dep)
+
+	first, err := u.CreateSearch(context.Background(), oneWayInput)
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+	waitForJob(t, u, first.ID, hasStatus(SearchJobRunning))
+	if _, err := u.CreateSearch(context.Background(), oneWayInput); err != nil {
+		t.Fatalf("expected the second search to be queued, got %v", err)
+	}
+
+	_, err = u.CreateSearch(context.Background(), oneWayInput)
+	var gerr *pkgerror.Error
+	if !errors.As(err, &gerr) || gerr.Code() != pkgerror.CodeUnavailable {
+		t.Fatalf("expected an unavailable error, got %v", err)
+	}
+}
+
+func TestCloseCancelsRunningSearchesWhenCtxExpires(t *testing.T) {
+	dep := testDependency(blockingProvider("Stub", make(chan struct{})))
+	dep.SearchJobs = SearchJobOptions{Workers: 1, QueueSize: 2, TTL: time.Minute}
+	u := New(dep)
+
+	running, err := u.CreateSearch(context.Background(), oneWayInput)
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+	waitForJob(t, u, running.ID, hasStatus(SearchJobRunning))
+	queued, err := u.CreateSearch(context.Background(), oneWayInput)
+	if err != nil {
+		t.Fatalf("CreateSearch: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() { closed <- u.Close(ctx) }()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected Close to report the expired context, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not return after its context expired")
+	}
+
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := u.SearchJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("SearchJob: %v", err)
+		}
+		if job.Status != SearchJobCanceled {
+			t.Fatalf("expected job %s to be canceled, got %s", id, job.Status)
+		}
+	}
+	_, err = u.CreateSearch(context.Background(), oneWayInput)
+	var gerr *pkgerror.Error
+	if !errors.As(err, &gerr) || gerr.Code() != pkgerror.CodeUnavailable {
+		t.Fatalf("expected new searches to be refused after Close, got %v", err)
+	}
+}
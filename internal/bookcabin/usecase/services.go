@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"slices"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+// IsAmenity reports whether value is part of the normalized amenity
+// vocabulary.
+func IsAmenity(value string) bool {
+	switch value {
+	case entity.AmenityWifi, entity.AmenityMeal, entity.AmenityEntertainment, entity.AmenityPower:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCheckedBaggage reports whether value is a supported checked_baggage filter.
+func IsCheckedBaggage(value string) bool {
+	return value == CheckedBaggageIncluded || value == CheckedBaggageNotIncluded
+}
+
+// matchServiceFilter checks the amenity and baggage filters. A flight must
+// offer every requested amenity; a minimum checked weight only matches
+// allowances that state their weight.
+func matchServiceFilter(f entity.Flight, filters FlightFilters) bool {
+	for _, amenity := range filters.Amenities {
+		if !slices.Contains(f.Amenities, amenity) {
+			return false
+		}
+	}
+
+	checked := f.Baggage.Checked
+	switch filters.CheckedBaggage {
+	case CheckedBaggageIncluded:
+		if !checked.Included {
+			return false
+		}
+	case CheckedBaggageNotIncluded:
+		if checked.Included {
+			return false
+		}
+	}
+	if filters.MinCheckedKg != nil && (!checked.Included || checked.WeightKg < *filters.MinCheckedKg) {
+		return false
+	}
+	return true
+}
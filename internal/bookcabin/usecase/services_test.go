@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func TestFlightsServiceFilters(t *testing.T) {
+	garuda := stubFlight("GA400", "CGK", "DPS", at(15, 6), 1250000)
+	garuda.Amenities = []string{entity.AmenityWifi, entity.AmenityMeal}
+	garuda.Baggage.Checked = entity.BaggageAllowance{Included: true, WeightKg: 20}
+	lion := stubFlight("JT740", "CGK", "DPS", at(15, 8), 780000)
+	lion.Amenities = []string{entity.AmenityMeal}
+	lion.Baggage.Checked = entity.BaggageAllowance{Included: true, Pieces: 1}
+	airasia := stubFlight("QZ650", "CGK", "DPS", at(15, 10), 650000)
+	airasia.Baggage.Checked = entity.BaggageAllowance{Included: false}
+	u := newTestUsecase(t, &stubProvider{flights: []entity.Flight{garuda, lion, airasia}})
+
+	minKg := func(kg int) *int { return &kg }
+	tests := []struct {
+		name    string
+		filters FlightFilters
+		wantIDs []string
+	}{
+		{name: "no filter", wantIDs: []string{"GA400", "JT740", "QZ650"}},
+		{name: "one amenity", filters: FlightFilters{Amenities: []string{entity.AmenityMeal}}, wantIDs: []string{"GA400", "JT740"}},
+		{name: "every amenity is required", filters: FlightFilters{Amenities: []string{entity.AmenityMeal, entity.AmenityWifi}}, wantIDs: []string{"GA400"}},
+		{name: "checked baggage included", filters: FlightFilters{CheckedBaggage: CheckedBaggageIncluded}, wantIDs: []string{"GA400", "JT740"}},
+		{name: "checked baggage not included", filters: FlightFilters{CheckedBaggage: CheckedBaggageNotIncluded}, wantIDs: []string{"QZ650"}},
+		{name: "minimum weight", filters: FlightFilters{MinCheckedKg: minKg(20)}, wantIDs: []string{"GA400"}},
+		{name: "pieces never match a weight", filters: FlightFilters{MinCheckedKg: minKg(1)}, wantIDs: []string{"GA400"}},
+		{name: "weight above every allowance", filters: FlightFilters{MinCheckedKg: minKg(25)}, wantIDs: []string{}},
+		{name: "zero weight", filters: FlightFilters{MinCheckedKg: minKg(0)}, wantIDs: []string{"GA400", "JT740"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := oneWayInput
+			in.Filters = tt.filters
+			in.Sort = []SortOption{{Field: SortDeparture}}
+			out, err := u.Flights(context.Background(), in)
+			if err != nil {
+				t.Fatalf("Flights: %v", err)
+			}
+			if got := flightIDs(out.Flights); !reflect.DeepEqual(got, tt.wantIDs) {
+				t.Fatalf("expected %v, got %v", tt.wantIDs, got)
+			}
+		})
+	}
+}
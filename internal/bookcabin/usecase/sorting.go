@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+const (
+	SortPrice        = "price"
+	SortDuration     = "duration"
+	SortDeparture    = "departure"
+	SortArrival      = "arrival"
+	SortArrivalLocal = "arrival_local"
+	SortBestValue    = "best_value"
+	SortStops        = "stops"
+	SortSeats        = "seats"
+	SortAirline      = "airline"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// SortOption is one key of a multi-key sort; later keys only break ties of
+// the earlier ones.
+type SortOption struct {
+	Field string
+	Order string
+}
+
+// IsSortField reports whether field is a supported sort key.
+func IsSortField(field string) bool {
+	switch field {
+	case SortPrice, SortDuration, SortDeparture, SortArrival, SortArrivalLocal,
+		SortBestValue, SortStops, SortSeats, SortAirline:
+		return true
+	default:
+		return false
+	}
+}
+
+func defaultSort(keys []SortOption) []SortOption {
+	if len(keys) == 0 {
+		return []SortOption{{Field: SortBestValue, Order: SortAsc}}
+	}
+	return keys
+}
+
+// sortFlights orders by every key in turn. Flights equal on all keys are
+// ordered by flight key and ID, so the result does not depend on the order
+// providers answered in.
+func sortFlights(flights []entity.Flight, keys []SortOption) {
+	keys = defaultSort(keys)
+	slices.SortStableFunc(flights, func(a, b entity.Flight) int {
+		for _, key := range keys {
+			c := compareFlights(a, b, key.Field)
+			if key.Order == SortDesc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Or(strings.Compare(flightKey(a), flightKey(b)), strings.Compare(a.ID, b.ID))
+	})
+}
+
+func compareFlights(a, b entity.Flight, field string) int {
+	switch field {
+	case SortPrice:
+		return cmp.Compare(a.Price.Amount, b.Price.Amount)
+	case SortDuration:
+		return cmp.Compare(a.DurationMinute, b.DurationMinute)
+	case SortDeparture:
+		return a.Departure.Time.Compare(b.Departure.Time)
+	case SortArrival:
+		return a.Arrival.Time.Compare(b.Arrival.Time)
+	case SortArrivalLocal:
+		return wallClock(a.Arrival.Time).Compare(wallClock(b.Arrival.Time))
+	case SortStops:
+		return cmp.Compare(a.Stops, b.Stops)
+	case SortSeats:
+		return cmp.Compare(a.AvailableSeats, b.AvailableSeats)
+	case SortAirline:
+		return cmp.Or(strings.Compare(a.Airline.Name, b.Airline.Name), strings.Compare(a.Airline.Code, b.Airline.Code))
+	default:
+		return cmp.Compare(a.BestValueScore, b.BestValueScore)
+	}
+}
+
+// sortItineraries applies the same keys to round trips: departure is the
+// outbound departure, arrival the return arrival, stops add up, and seats are
+// the fewer of both legs.
+func sortItineraries(itineraries []entity.Itinerary, keys []SortOption) {
+	keys = defaultSort(keys)
+	slices.SortStableFunc(itineraries, func(a, b entity.Itinerary) int {
+		for _, key := range keys {
+			c := compareItineraries(a, b, key.Field)
+			if key.Order == SortDesc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+func compareItineraries(a, b entity.Itinerary, field string) int {
+	switch field {
+	case SortPrice:
+		return cmp.Compare(a.Price.Amount, b.Price.Amount)
+	case SortDuration:
+		return cmp.Compare(a.DurationMinute, b.DurationMinute)
+	case SortDeparture:
+		return a.Outbound.Departure.Time.Compare(b.Outbound.Departure.Time)
+	case SortArrival:
+		return a.Return.Arrival.Time.Compare(b.Return.Arrival.Time)
+	case SortArrivalLocal:
+		return wallClock(a.Return.Arrival.Time).Compare(wallClock(b.Return.Arrival.Time))
+	case SortStops:
+		return cmp.Compare(a.Outbound.Stops+a.Return.Stops, b.Outbound.Stops+b.Return.Stops)
+	case SortSeats:
+		return cmp.Compare(
+			min(a.Outbound.AvailableSeats, a.Return.AvailableSeats),
+			min(b.Outbound.AvailableSeats, b.Return.AvailableSeats),
+		)
+	case SortAirline:
+		return compareFlights(a.Outbound, b.Outbound, SortAirline)
+	default:
+		return cmp.Compare(a.BestValueScore, b.BestValueScore)
+	}
+}
+
+// wallClock drops the zone so times compare by their local reading: 09:00
+// WITA sorts after 08:30 WIB even though it is the earlier instant.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+func flightIDs(flights []entity.Flight) []string {
+	ids := make([]string, 0, len(flights))
+	for _, f := range flights {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestSortFlights(t *testing.T) {
+	wita := time.FixedZone("WITA", 8*60*60)
+	// GA400 and JT740 cost the same; JT740 and QZ650 leave at the same time.
+	ga := stubFlight("GA400", "CGK", "DPS", at(15, 6), 800000)
+	ga.Stops = 1
+	ga.AvailableSeats = 5
+	jt := stubFlight("JT740", "CGK", "DPS", at(15, 8), 800000)
+	jt.BestValueScore = 0.2
+	qz := stubFlight("QZ650", "CGK", "DPS", at(15, 8), 600000)
+	qz.BestValueScore = 0.1
+	qz.AvailableSeats = 9
+	// QZ650 lands last as an instant but first on the wall clock.
+	ga.Arrival.Time = time.Date(2025, 12, 15, 10, 30, 0, 0, wita)
+	jt.Arrival.Time = time.Date(2025, 12, 15, 11, 0, 0, 0, wita)
+	qz.Arrival.Time = time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		keys []SortOption
+		want []string
+	}{
+		{name: "default is best value", want: []string{"GA400", "QZ650", "JT740"}},
+		{name: "single key", keys: []SortOption{{Field: SortPrice}}, want: []string{"QZ650", "GA400", "JT740"}},
+		{name: "descending", keys: []SortOption{{Field: SortPrice, Order: SortDesc}}, want: []string{"GA400", "JT740", "QZ650"}},
+		{name: "second key breaks ties", keys: []SortOption{{Field: SortPrice, Order: SortDesc}, {Field: SortDeparture, Order: SortDesc}}, want: []string{"JT740", "GA400", "QZ650"}},
+		{name: "ties fall back to the flight key", keys: []SortOption{{Field: SortDeparture, Order: SortDesc}}, want: []string{"JT740", "QZ650", "GA400"}},
+		{name: "stops then seats", keys: []SortOption{{Field: SortStops}, {Field: SortSeats, Order: SortDesc}}, want: []string{"JT740", "QZ650", "GA400"}},
+		{name: "arrival instant", keys: []SortOption{{Field: SortArrival}}, want: []string{"GA400", "JT740", "QZ650"}},
+		{name: "arrival wall clock", keys: []SortOption{{Field: SortArrivalLocal}}, want: []string{"QZ650", "GA400", "JT740"}},
+		{name: "airline", keys: []SortOption{{Field: SortAirline, Order: SortDesc}}, want: []string{"QZ650", "JT740", "GA400"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every input order has to give the same result.
+			for _, flights := range [][]entity.Flight{{ga, jt, qz}, {qz, jt, ga}, {jt, qz, ga}} {
+				flights = append([]entity.Flight(nil), flights...)
+				sortFlights(flights, tt.keys)
+				if got := flightIDs(flights); !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSortFlightsSameFlightFromSeveralProviders(t *testing.T) {
+	lion := stubFlight("JT740", "CGK", "DPS", at(15, 8), 800000)
+	lion.ID = "JT740_Lion Air"
+	other := stubFlight("JT740", "CGK", "DPS", at(15, 8), 800000)
+	other.ID = "JT740_Agent"
+
+	for _, flights := range [][]entity.Flight{{lion, other}, {other, lion}} {
+		sortFlights(flights, []SortOption{{Field: SortPrice}})
+		if got := flightIDs(flights); !reflect.DeepEqual(got, []string{"JT740_Agent", "JT740_Lion Air"}) {
+			t.Fatalf("expected copies of one flight ordered by ID, got %v", got)
+		}
+	}
+}
+
+func TestSortItineraries(t *testing.T) {
+	itinerary := func(id string, price, outStops, retStops int, returnArrival time.Time) entity.Itinerary {
+		it := entity.Itinerary{ID: id, Price: entity.Price{Amount: price}}
+		it.Outbound.Stops = outStops
+		it.Return.Stops = retStops
+		it.Return.Arrival.Time = returnArrival
+		return it
+	}
+	a := itinerary("A", 1500000, 1, 0, at(20, 12))
+	b := itinerary("B", 1500000, 0, 0, at(20, 10))
+	c := itinerary("C", 1200000, 1, 1, at(20, 11))
+
+	tests := []struct {
+		name string
+		keys []SortOption
+		want []string
+	}{
+		{name: "price then id", keys: []SortOption{{Field: SortPrice}}, want: []string{"C", "A", "B"}},
+		{name: "stops add up", keys: []SortOption{{Field: SortStops}}, want: []string{"B", "A", "C"}},
+		{name: "return arrival", keys: []SortOption{{Field: SortArrival, Order: SortDesc}}, want: []string{"A", "C", "B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			itineraries := []entity.Itinerary{b, c, a}
+			sortItineraries(itineraries, tt.keys)
+			ids := make([]string, 0, len(itineraries))
+			for _, it := range itineraries {
+				ids = append(ids, it.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
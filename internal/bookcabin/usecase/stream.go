@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+)
+
+type FlightsStreamEvent struct {
+	Leg         string
+	Provider    *ProviderStatus
+	Flights     []entity.Flight
+	ReplacedIDs []string
+}
+
+type flightStream struct {
+	mu     sync.Mutex
+	emit   func(FlightsStreamEvent)
+	sortBy []SortOption
+	dedup  string
+	sent   map[string]map[string]entity.Flight
+}
+
+// FlightsStream runs the regular search and emits the flights of every
+// provider as soon as it answers. Flights already sent for the same leg are
+// skipped unless the new offer is cheaper, in which case the event lists the
+// replaced flight IDs; with dedup off every flight is sent. Best value scores
+// need the full result set, so streamed batches skip the best value sort keys,
+// falling back to price order, and the final output carries the scores.
+func (u *Usecase) FlightsStream(ctx context.Context, in FlightsInput, emit func(FlightsStreamEvent)) (*FlightsOutput, error) {
+	sortBy := make([]SortOption, 0, len(in.Sort))
+	for _, key := range in.Sort {
+		if key.Field != SortBestValue {
+			sortBy = append(sortBy, key)
+		}
+	}
+	if len(sortBy) == 0 {
+		sortBy = []SortOption{{Field: SortPrice, Order: SortAsc}}
+	}
+	stream := &flightStream{emit: emit, sortBy: sortBy, dedup: in.Dedup, sent: map[string]map[string]entity.Flight{}}
+	return u.search(ctx, in, stream)
+}
+
+func (s *flightStream) provider(status ProviderStatus, flights []entity.Flight) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent, ok := s.sent[status.Leg]
+	if !ok {
+		sent = map[string]entity.Flight{}
+		s.sent[status.Leg] = sent
+	}
+
+	event := FlightsStreamEvent{
+		Leg:         status.Leg,
+		Provider:    &status,
+		Flights:     make([]entity.Flight, 0, len(flights)),
+		ReplacedIDs: make([]string, 0),
+	}
+	for _, flight := range flights {
+		if s.dedup == DedupOff {
+			event.Flights = append(event.Flights, flight)
+			continue
+		}
+		key := flightKey(flight)
+		previous, exists := sent[key]
+		if exists && flight.Price.Amount >= previous.Price.Amount {
+			continue
+		}
+		if exists {
+			event.ReplacedIDs = append(event.ReplacedIDs, previous.ID)
+		}
+		sent[key] = flight
+		event.Flights = append(event.Flights, flight)
+	}
+	sortFlights(event.Flights, s.sortBy)
+
+	s.emit(event)
+}
+
+func (s *flightStream) cached(output *FlightsOutput) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.emit(FlightsStreamEvent{Leg: LegOutbound, Flights: output.Flights, ReplacedIDs: []string{}})
+	if output.SearchCriteria.ReturnDate != nil {
+		s.emit(FlightsStreamEvent{Leg: LegReturn, Flights: output.ReturnFlights, ReplacedIDs: []string{}})
+	}
+}
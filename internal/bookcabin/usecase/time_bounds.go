@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+)
+
+// TimeBound is a departure or arrival time filter. A local bound (HH:MM) is a
+// wall-clock time on the date of the leg, read in the local time of the
+// airport it applies to; otherwise Instant is an absolute time.
+type TimeBound struct {
+	Instant time.Time
+	Local   bool
+	Hour    int
+	Minute  int
+}
+
+// localizeTimeFilters turns local bounds into instants for one airport pair:
+// departure bounds use the origin's zone and arrival bounds the
+// destination's, both on the departure date of the request.
+func (u *Usecase) localizeTimeFilters(filters FlightFilters, req provider.SearchRequest) FlightFilters {
+	originLoc := u.airportLocation(req.Origin)
+	destinationLoc := u.airportLocation(req.Destination)
+
+	clone := filters
+	clone.DepartAfter = localizeTimeBound(filters.DepartAfter, req.DepartureDate, originLoc)
+	clone.DepartBefore = localizeTimeBound(filters.DepartBefore, req.DepartureDate, originLoc)
+	clone.ArriveAfter = localizeTimeBound(filters.ArriveAfter, req.DepartureDate, destinationLoc)
+	clone.ArriveBefore = localizeTimeBound(filters.ArriveBefore, req.DepartureDate, destinationLoc)
+	return clone
+}
+
+func localizeTimeBound(bound *TimeBound, date time.Time, loc *time.Location) *TimeBound {
+	if bound == nil || !bound.Local {
+		return bound
+	}
+	instant := time.Date(date.Year(), date.Month(), date.Day(), bound.Hour, bound.Minute, 0, 0, loc)
+	return &TimeBound{Instant: instant}
+}
+
+// airportLocation falls back to the server's zone for airports missing from
+// the registry, which is how HH:MM filters were read before.
+func (u *Usecase) airportLocation(code string) *time.Location {
+	if u.airports != nil {
+		if a, ok := u.airports.Airport(code); ok && a.Location != nil {
+			return a.Location
+		}
+	}
+	return time.Local
+}
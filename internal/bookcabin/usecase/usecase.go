@@ -3,32 +3,101 @@ package usecase
 import (
 	"time"
 
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airline"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/airport"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/cache"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
 	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkguid"
 )
 
+type ProviderOption struct {
+	Timeout time.Duration
+	Retries int
+}
+
 type Dependency struct {
-	Providers          []provider.Provider
-	Cache              *cache.Cache[*FlightsOutput]
-	CacheTTL           time.Duration
-	ProviderTimeout    time.Duration
-	MaxProviderRetries int
+	Providers           []provider.Provider
+	ProviderOptions     map[string]ProviderOption
+	Cache               *cache.Cache[*FlightsOutput]
+	CacheTTL            time.Duration
+	ProviderTimeout     time.Duration
+	MaxProviderRetries  int
+	Rates               currency.Rates
+	DefaultCurrency     string
+	SearchDeadline      time.Duration
+	Hedge               *HedgeOptions
+	IDs                 pkguid.StringID
+	SearchJobs          SearchJobOptions
+	CalendarConcurrency int
+	Airports            airport.Directory
+	Airlines            airline.Directory
+	Scoring             ScoringStrategy
+	ScoreWeights        ScoreWeights
 }
 
 type Usecase struct {
-	providers          []provider.Provider
-	cache              *cache.Cache[*FlightsOutput]
-	cacheTTL           time.Duration
-	providerTimeout    time.Duration
-	maxProviderRetries int
+	providers           []provider.Provider
+	providerOptions     map[string]ProviderOption
+	cache               *cache.Cache[*FlightsOutput]
+	cacheTTL            time.Duration
+	providerTimeout     time.Duration
+	maxProviderRetries  int
+	rates               currency.Rates
+	defaultCurrency     string
+	searchDeadline      time.Duration
+	hedge               HedgeOptions
+	latencies           *latencyTracker
+	jobs                *searchJobs
+	calendarConcurrency int
+	airports            airport.Directory
+	airlines            airline.Directory
+	scoring             ScoringStrategy
+	scoreWeights        ScoreWeights
 }
 
 func New(dep Dependency) *Usecase {
-	return &Usecase{
-		providers:          dep.Providers,
-		cache:              dep.Cache,
-		cacheTTL:           dep.CacheTTL,
-		providerTimeout:    dep.ProviderTimeout,
-		maxProviderRetries: dep.MaxProviderRetries,
+	u := &Usecase{
+		providers:           dep.Providers,
+		providerOptions:     dep.ProviderOptions,
+		cache:               dep.Cache,
+		cacheTTL:            dep.CacheTTL,
+		providerTimeout:     dep.ProviderTimeout,
+		maxProviderRetries:  dep.MaxProviderRetries,
+		rates:               dep.Rates,
+		defaultCurrency:     dep.DefaultCurrency,
+		searchDeadline:      dep.SearchDeadline,
+		jobs:                newSearchJobs(dep.SearchJobs, dep.IDs),
+		calendarConcurrency: dep.CalendarConcurrency,
+		airports:            dep.Airports,
+		airlines:            dep.Airlines,
+		scoring:             dep.Scoring,
+		scoreWeights:        dep.ScoreWeights,
+	}
+	if u.scoring == nil {
+		u.scoring = WeightedScoring{}
+	}
+	if u.scoreWeights == nil {
+		u.scoreWeights = DefaultScoreWeights()
+	}
+	if dep.Hedge != nil && dep.Hedge.Percentile > 0 {
+		u.hedge = *dep.Hedge
+		u.latencies = newLatencyTracker(max(dep.Hedge.WindowSize, dep.Hedge.MinSamples, 1))
+	}
+	u.startSearchWorkers(max(dep.SearchJobs.Workers, 1))
+	return u
+}
+
+func (u *Usecase) providerTimeoutFor(name string) time.Duration {
+	if opt, ok := u.providerOptions[name]; ok && opt.Timeout > 0 {
+		return opt.Timeout
+	}
+	return u.providerTimeout
+}
+
+func (u *Usecase) providerRetriesFor(name string) int {
+	if opt, ok := u.providerOptions[name]; ok && opt.Retries >= 0 {
+		return opt.Retries
 	}
+	return u.maxProviderRetries
 }
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/cache"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/currency"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/entity"
+	"github.com/shandysiswandi/gobookcabin/internal/bookcabin/provider"
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkguid"
+)
+
+// stubProvider returns flights unless search is set, in which case search
+// decides per request.
+type stubProvider struct {
+	name    string
+	flights []entity.Flight
+	search  func(ctx context.Context, req provider.SearchRequest) ([]entity.Flight, error)
+	calls   atomic.Int32
+}
+
+func (s *stubProvider) Name() string {
+	if s.name == "" {
+		return "Stub"
+	}
+	return s.name
+}
+
+func (s *stubProvider) Search(ctx context.Context, req provider.SearchRequest) ([]entity.Flight, error) {
+	s.calls.Add(1)
+	if s.search != nil {
+		return s.search(ctx, req)
+	}
+	return s.flights, nil
+}
+
+// stubRates quotes every currency against IDR.
+type stubRates map[string]float64
+
+func (s stubRates) Rate(_ context.Context, from, to string) (float64, error) {
+	perIDR := func(code string) (float64, bool) {
+		if code == "IDR" {
+			return 1, true
+		}
+		rate, ok := s[code]
+		return rate, ok
+	}
+	fromRate, ok := perIDR(from)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", currency.ErrUnsupported, from)
+	}
+	toRate, ok := perIDR(to)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", currency.ErrUnsupported, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// oneWayInput searches CGK to DPS on 15 December for one adult.
+var oneWayInput = FlightsInput{Origin: "CGK", Destination: "DPS", DepartureDate: at(15, 0), Passengers: entity.Passengers{Adults: 1}}
+
+// at returns the given hour of a December 2025 day in UTC.
+func at(day, hour int) time.Time {
+	return time.Date(2025, 12, day, hour, 0, 0, 0, time.UTC)
+}
+
+// stubFlight builds a two hour nonstop economy flight; the airline is taken
+// from the first two characters of id.
+func stubFlight(id, origin, destination string, depart time.Time, price int) entity.Flight {
+	return entity.Flight{
+		ID:             id,
+		Provider:       "Stub",
+		Airline:        entity.Airline{Code: id[:2], Name: id[:2]},
+		FlightNumber:   id,
+		Departure:      entity.FlightPoint{Airport: origin, Time: depart},
+		Arrival:        entity.FlightPoint{Airport: destination, Time: depart.Add(2 * time.Hour)},
+		DurationMinute: 120,
+		Price:          entity.Price{Amount: price, Currency: "IDR"},
+		AvailableSeats: 20,
+		CabinClass:     "economy",
+	}
+}
+
+func testDependency(providers ...provider.Provider) Dependency {
+	return Dependency{
+		Providers:       providers,
+		Cache:           cache.New(CloneFlightsOutput),
+		CacheTTL:        time.Minute,
+		ProviderTimeout: time.Second,
+		Rates:           stubRates{"USD": 0.0000625, "SGD": 0.0000833},
+		DefaultCurrency: "IDR",
+		SearchJobs:      SearchJobOptions{Workers: 1, QueueSize: 4, TTL: time.Minute},
+		IDs:             pkguid.NewUUID(),
+	}
+}
+
+func newTestUsecase(t *testing.T, providers ...provider.Provider) *Usecase {
+	t.Helper()
+	return newUsecaseFrom(t, testDependency(providers...))
+}
+
+// newUsecaseFrom builds a usecase whose search workers stop with the test.
+func newUsecaseFrom(t *testing.T, dep Dependency) *Usecase {
+	t.Helper()
+	u := New(dep)
+	t.Cleanup(func() { _ = u.Close(context.Background()) })
+	return u
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
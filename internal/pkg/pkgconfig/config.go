@@ -44,4 +44,9 @@ type Config interface {
 	// the implementation should handle it accordingly (e.g., return a default value).
 	// Configuration value is stored with format <key1>:<value1>,<key2>:<value2>,...
 	GetMap(key string) map[string]string
+
+	// GetKeys retrieves the names of the direct children of the configuration section
+	// associated with the given key, sorted alphabetically.
+	// If the key does not exist or is not a section, the implementation should return nil.
+	GetKeys(key string) []string
 }
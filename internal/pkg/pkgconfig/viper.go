@@ -3,6 +3,7 @@ package pkgconfig
 import (
 	"encoding/base64"
 	"path"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -86,6 +87,22 @@ func (vc *Viper) GetMap(key string) map[string]string {
 	return m
 }
 
+// GetKeys returns the sorted names of the direct children of the section at key.
+func (vc *Viper) GetKeys(key string) []string {
+	section := vc.v.GetStringMap(key)
+	if len(section) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(section))
+	for k := range section {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // Close implements io.Closer for interface compatibility.
 func (vc *Viper) Close() error {
 	// No resources to close for ViperConfig; this is just for interface completeness.
@@ -64,3 +64,24 @@ func TestViperGetBinaryInvalid(t *testing.T) {
 		t.Fatalf("expected nil for invalid base64, got %v", got)
 	}
 }
+
+func TestViperGetKeys(t *testing.T) {
+	path := writeConfigFile(t, "providers:\n  lion:\n    type: lion_air\n  garuda:\n    type: garuda\n    enabled: false\nscalar: 1\n")
+	cfg, err := NewViper(path)
+	if err != nil {
+		t.Fatalf("NewViper: %v", err)
+	}
+
+	if got := cfg.GetKeys("providers"); !reflect.DeepEqual(got, []string{"garuda", "lion"}) {
+		t.Fatalf("GetKeys: unexpected value: %#v", got)
+	}
+	if got := cfg.GetKeys("providers.garuda"); !reflect.DeepEqual(got, []string{"enabled", "type"}) {
+		t.Fatalf("GetKeys nested: unexpected value: %#v", got)
+	}
+	if got := cfg.GetKeys("scalar"); got != nil {
+		t.Fatalf("GetKeys scalar: expected nil, got %#v", got)
+	}
+	if got := cfg.GetKeys("missing"); got != nil {
+		t.Fatalf("GetKeys missing: expected nil, got %#v", got)
+	}
+}
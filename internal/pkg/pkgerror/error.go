@@ -45,6 +45,7 @@ const (
 	CodeUnauthorized              // Error code for unauthorized access.
 	CodeForbidden                 // Error code for forbidden actions.
 	CodeTimeout                   // Error code for operation timeout.
+	CodeUnavailable               // Error code for a temporarily unavailable service (e.g., overloaded).
 )
 
 func (c Code) String() string {
@@ -61,6 +62,8 @@ func (c Code) String() string {
 		return "ERROR_CODE_UNAUTHORIZED"
 	case CodeForbidden:
 		return "ERROR_CODE_FORBIDDEN"
+	case CodeUnavailable:
+		return "ERROR_CODE_UNAVAILABLE"
 	case CodeInternal:
 		return "ERROR_CODE_INTERNAL"
 	default:
@@ -152,6 +155,8 @@ func (e *Error) StatusCode() int {
 		return http.StatusRequestTimeout
 	case CodeConflict:
 		return http.StatusConflict
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
 	case CodeInternal:
 		return http.StatusInternalServerError
 	default:
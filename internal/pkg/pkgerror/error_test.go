@@ -73,6 +73,14 @@ func TestBusinessAndValidationErrors(t *testing.T) {
 		t.Fatalf("unexpected business status: %d", got)
 	}
 
+	unavailable := NewBusiness("busy", CodeUnavailable).(*Error)
+	if got := unavailable.StatusCode(); got != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected unavailable status: %d", got)
+	}
+	if got := CodeUnavailable.String(); got != "ERROR_CODE_UNAVAILABLE" {
+		t.Fatalf("unexpected unavailable string: %q", got)
+	}
+
 	root := errors.New("bad")
 	invalidInput := NewInvalidInput(root)
 	if got := invalidInput.Error(); got != "bad" {
@@ -85,7 +85,7 @@ func (w *statusRecorder) Write(p []byte) (int, error) {
 		w.status = http.StatusOK
 	}
 
-	if w.body != nil && !w.capped && len(p) > 0 {
+	if w.body != nil && !w.capped && len(p) > 0 && !w.streaming() {
 		remaining := maxLoggedBodyBytes - w.body.Len()
 		if remaining > 0 {
 			if len(p) > remaining {
@@ -104,10 +104,25 @@ func (w *statusRecorder) Write(p []byte) (int, error) {
 	return n, err
 }
 
+// streaming reports whether the response is a server-sent event stream, whose
+// body is not captured for logging.
+func (w *statusRecorder) streaming() bool {
+	return strings.HasPrefix(w.Header().Get("Content-Type"), EventStreamContentType)
+}
+
 func (w *statusRecorder) Flush() {
-	if f, ok := w.ResponseWriter.(http.Flusher); ok {
-		f.Flush()
-	}
+	//nolint:errcheck // http.Flusher has no way to report the error
+	_ = w.FlushError()
+}
+
+// FlushError flushes the underlying writer and reports when flushing is not supported.
+func (w *statusRecorder) FlushError() error {
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 //nolint:err113 // it use dynamic error
@@ -203,7 +218,9 @@ func middlewareLogging(next http.Handler) http.Handler {
 		}
 
 		var respBody any
-		if rec.body != nil {
+		if rec.streaming() {
+			respBody = "<event stream omitted>"
+		} else if rec.body != nil {
 			var respJSON any
 			if err := json.Unmarshal(rec.body.Bytes(), &respJSON); err == nil {
 				respBody = maskData(respJSON)
@@ -0,0 +1,84 @@
+package pkgrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+// EventStreamContentType is the Content-Type used for server-sent event responses.
+const EventStreamContentType = "text/event-stream"
+
+// StreamWriter sends server-sent events to the client.
+type StreamWriter interface {
+	// Send writes a single event with a JSON encoded payload and flushes it to the client.
+	Send(event string, data any) error
+}
+
+// StreamHandler is the streaming counterpart of Handler.
+//
+// An error returned before the first event is encoded like a regular Handler error.
+// Once events have been sent, the error is reported as a final "error" event.
+type StreamHandler func(ctx context.Context, r *http.Request, stream StreamWriter) error
+
+// GETStream registers a GET endpoint that streams server-sent events.
+func (r *Router) GETStream(path string, h StreamHandler, mws ...Middleware) {
+	r.hr.Handler(http.MethodGet, path, Chain(http.HandlerFunc(func(w http.ResponseWriter, re *http.Request) {
+		stream := &sseWriter{w: w, rc: http.NewResponseController(w)}
+		err := h(re.Context(), re, stream)
+		if err == nil {
+			return
+		}
+		if !stream.started {
+			r.errorCodec(re.Context(), w, err)
+			return
+		}
+		//nolint:errcheck // the client is gone when the error event cannot be written
+		_ = stream.Send("error", streamErrorPayload(err))
+	}), append(r.mws, mws...)...))
+}
+
+type sseWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	started bool
+}
+
+func (s *sseWriter) Send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode event %q: %w", event, err)
+	}
+
+	if !s.started {
+		header := s.w.Header()
+		header.Set("Content-Type", EventStreamContentType)
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+		s.w.WriteHeader(http.StatusOK)
+		s.started = true
+	}
+
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", sanitizeEventName(event), payload); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func sanitizeEventName(event string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(event)
+}
+
+func streamErrorPayload(err error) errorResponse {
+	var gerr *pkgerror.Error
+	if errors.As(err, &gerr) {
+		return errorResponse{Message: gerr.Msg()}
+	}
+	return errorResponse{Message: "Internal server error"}
+}
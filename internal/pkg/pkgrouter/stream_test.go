@@ -0,0 +1,168 @@
+package pkgrouter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shandysiswandi/gobookcabin/internal/pkg/pkgerror"
+)
+
+func readEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestGETStreamFlushesEachEvent(t *testing.T) {
+	proceed := make(chan struct{})
+	router := NewRouter(&staticGenerator{value: "cid"})
+	router.GETStream("/events", func(ctx context.Context, _ *http.Request, stream StreamWriter) error {
+		if err := stream.Send("first", map[string]int{"n": 1}); err != nil {
+			return err
+		}
+		select {
+		case <-proceed:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			return errors.New("first event was not received before the timeout")
+		}
+		return stream.Send("second", map[string]int{"n": 2})
+	})
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != EventStreamContentType {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	event, data := readEvent(t, reader)
+	if event != "first" || data != `{"n":1}` {
+		t.Fatalf("unexpected first event %q: %s", event, data)
+	}
+	close(proceed)
+
+	event, data = readEvent(t, reader)
+	if event != "second" || data != `{"n":2}` {
+		t.Fatalf("unexpected second event %q: %s", event, data)
+	}
+}
+
+func TestGETStreamErrorBeforeFirstEvent(t *testing.T) {
+	router := NewRouter(&staticGenerator{value: "cid"})
+	router.GETStream("/events", func(_ context.Context, _ *http.Request, _ StreamWriter) error {
+		return pkgerror.NewBusiness("bad input", pkgerror.CodeInvalidInput)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	var body errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Message != "bad input" {
+		t.Fatalf("unexpected message %q", body.Message)
+	}
+}
+
+func TestGETStreamErrorAfterFirstEvent(t *testing.T) {
+	router := NewRouter(&staticGenerator{value: "cid"})
+	router.GETStream("/events", func(_ context.Context, _ *http.Request, stream StreamWriter) error {
+		if err := stream.Send("first", map[string]int{"n": 1}); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	reader := bufio.NewReader(strings.NewReader(rec.Body.String()))
+	readEvent(t, reader)
+	event, data := readEvent(t, reader)
+	if event != "error" || data != `{"message":"Internal server error"}` {
+		t.Fatalf("unexpected error event %q: %s", event, data)
+	}
+}
+
+type plainWriter struct {
+	header http.Header
+}
+
+func (w *plainWriter) Header() http.Header         { return w.header }
+func (w *plainWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *plainWriter) WriteHeader(int)             {}
+
+func TestStatusRecorderFlush(t *testing.T) {
+	inner := httptest.NewRecorder()
+	rec := &statusRecorder{ResponseWriter: inner}
+
+	if err := http.NewResponseController(rec).Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if !inner.Flushed {
+		t.Fatal("expected flush to reach the underlying writer")
+	}
+	if rec.Unwrap() != inner {
+		t.Fatal("expected Unwrap to return the underlying writer")
+	}
+
+	unsupported := &statusRecorder{ResponseWriter: &plainWriter{header: http.Header{}}}
+	if err := http.NewResponseController(unsupported).Flush(); !errors.Is(err, http.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestStatusRecorderSkipsEventStreamBody(t *testing.T) {
+	inner := httptest.NewRecorder()
+	inner.Header().Set("Content-Type", EventStreamContentType)
+	rec := &statusRecorder{ResponseWriter: inner, body: &bytes.Buffer{}}
+
+	if _, err := io.WriteString(rec, "event: ping\ndata: {}\n\n"); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if rec.body.Len() != 0 {
+		t.Fatalf("expected event stream body not to be captured, got %q", rec.body.String())
+	}
+	if rec.bytes == 0 || inner.Body.Len() == 0 {
+		t.Fatal("expected bytes to reach the client")
+	}
+}